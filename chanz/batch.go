@@ -0,0 +1,96 @@
+package chanz
+
+import "time"
+
+// Batch takes a chan and returns a chan of slices. Elements are collected into a batch which is put on the returning
+// chan once it holds maxSize elements, or once maxWait has passed since the first element of the batch was received,
+// whichever comes first.
+// A maxSize < 1 means that batches are only limited by time, and a maxWait <= 0 that they are only limited by size.
+// Once "in" is closed, the last, partial, batch is flushed before the return chan is closed. If "done" is closed,
+// the partial batch is only flushed if it can be put on the return chan without blocking.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	rows := chanz.Generate(1, 2, 3, 4, 5)
+//	batches := chanz.Batch(rows, 2, time.Second)
+//	result := chanz.Collect(batches)
+//	// result = [][]int{{1, 2}, {3, 4}, {5}}
+func Batch[A any](in <-chan A, maxSize int, maxWait time.Duration, options ...Option) <-chan []A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan []A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		clock := s.getClock()
+		var batch []A
+		var timer Timer
+		var timeout <-chan time.Time
+		reset := func() {
+			batch = nil
+			timeout = nil
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		defer reset()
+
+		for {
+			select {
+			case <-s.stop():
+				if len(batch) > 0 {
+					select {
+					case out <- batch:
+					default:
+					}
+				}
+				return
+			case e, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						send(s, out, batch)
+					}
+					return
+				}
+				s.observe(EventReceived, 0)
+				batch = append(batch, e)
+				if len(batch) == 1 && maxWait > 0 {
+					timer = clock.NewTimer(maxWait)
+					timeout = timer.C()
+				}
+				if maxSize < 1 || len(batch) < maxSize {
+					continue
+				}
+			case <-timeout:
+			}
+
+			if !send(s, out, batch) {
+				return
+			}
+			reset()
+		}
+	}()
+	return out
+}
+
+// BatchWith returns a configured Batch function closure.
+// Allows creating reusable batchers with preset options.
+//
+// Example:
+//
+//	batcher := chanz.BatchWith[Row](chanz.OpContext(ctx))
+//	for rows := range batcher(incoming, 500, 100*time.Millisecond) {
+//	    insert(rows)
+//	}
+func BatchWith[A any](options ...Option) func(in <-chan A, maxSize int, maxWait time.Duration) <-chan []A {
+	return func(in <-chan A, maxSize int, maxWait time.Duration) <-chan []A {
+		return Batch(in, maxSize, maxWait, options...)
+	}
+}
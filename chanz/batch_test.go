@@ -0,0 +1,83 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestBatchSize(t *testing.T) {
+	res := Collect(Batch(Generate(1, 2, 3, 4, 5), 2, time.Hour))
+
+	exp := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slicez.EqualBy(exp, res, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestBatchWait(t *testing.T) {
+	in := make(chan int)
+	batches := Batch(in, 100, 20*time.Millisecond)
+
+	start := time.Now()
+	in <- 1
+	in <- 2
+	res := <-batches
+	if !slicez.Equal([]int{1, 2}, res) {
+		t.Errorf("expected [1 2], got %v", res)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected batch to be held until max wait had passed")
+	}
+
+	in <- 3
+	close(in)
+	res = <-batches
+	if !slicez.Equal([]int{3}, res) {
+		t.Errorf("expected final flush of [3], got %v", res)
+	}
+	if _, ok := <-batches; ok {
+		t.Error("expected output to be closed")
+	}
+}
+
+func TestBatchUnboundedSize(t *testing.T) {
+	res := Collect(Batch(Generate(1, 2, 3, 4, 5), 0, time.Hour))
+
+	exp := [][]int{{1, 2, 3, 4, 5}}
+	if !slicez.EqualBy(exp, res, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestBatchContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	batches := Batch(in, 10, time.Hour, OpContext(ctx), OpBuffer(1))
+
+	in <- 1
+	in <- 2
+	cancel()
+
+	res := Collect(batches)
+	exp := [][]int{{1, 2}}
+	if !slicez.EqualBy(exp, res, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestBatchWith(t *testing.T) {
+	batcher := BatchWith[int](OpBuffer(2))
+	res := Collect(batcher(Generate(1, 2, 3), 3, 0))
+
+	exp := [][]int{{1, 2, 3}}
+	if !slicez.EqualBy(exp, res, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
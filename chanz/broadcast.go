@@ -0,0 +1,141 @@
+package chanz
+
+import "sync"
+
+// Backpressure decides what Broadcast does with an element when a subscriber's buffer is full.
+type Backpressure int
+
+const (
+	// BackpressureBlock waits until the subscriber has room, stalling every other subscriber meanwhile.
+	BackpressureBlock Backpressure = iota
+	// BackpressureDropNewest discards the element that did not fit.
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest buffered element to make room for the new one.
+	BackpressureDropOldest
+	// BackpressureDisconnect closes the subscriber's channel and stops sending to it.
+	BackpressureDisconnect
+)
+
+// Broadcast splits one input channel into one output channel per supplied policy.
+// Like FanOut, each value from the input is sent to all outputs, but every subscriber is served by its own
+// goroutine and buffer, so a stalled subscriber only affects the others if its policy is BackpressureBlock.
+// The buffer of each subscriber has the buffer size supplied in input Option, default is 0 which is treated as 1.
+// Output channels are closed when input closes.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// also while waiting on a subscriber.
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3)
+//	outputs := chanz.Broadcast(input, []chanz.Backpressure{
+//	    chanz.BackpressureBlock,      // e.g. a audit log, must see everything
+//	    chanz.BackpressureDropOldest, // e.g. a dashboard, only cares about the latest values
+//	}, chanz.OpBuffer(10))
+func Broadcast[A any](c <-chan A, policies []Backpressure, options ...Option) []<-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	size := s.buffer
+	if size < 1 {
+		size = 1
+	}
+
+	queues := make([]chan A, len(policies))
+	outs := make([]chan A, len(policies))
+	var wg sync.WaitGroup
+	s.started()
+	wg.Add(len(policies))
+	for i := range policies {
+		queues[i] = make(chan A, size)
+		outs[i] = make(chan A)
+		go func(queue <-chan A, out chan<- A) {
+			defer wg.Done()
+			defer close(out)
+			for {
+				select {
+				case <-s.stop():
+					return
+				case e, ok := <-queue:
+					if !ok {
+						return
+					}
+					if !send(s, out, e) {
+						return
+					}
+				}
+			}
+		}(queues[i], outs[i])
+	}
+
+	go func() {
+		connected := make([]bool, len(queues))
+		for i := range connected {
+			connected[i] = true
+		}
+		defer s.finished(func() {
+			for i, q := range queues {
+				if connected[i] {
+					close(q)
+				}
+			}
+			wg.Wait() // the subscribers close the outputs once their queue is drained
+		})
+
+		for e := range c {
+			s.observe(EventReceived, 0)
+			for i, q := range queues {
+				if !connected[i] {
+					continue
+				}
+				switch policies[i] {
+				case BackpressureBlock:
+					select {
+					case <-s.stop():
+						return
+					case q <- e:
+					}
+				case BackpressureDropNewest:
+					select {
+					case q <- e:
+					default:
+					}
+				case BackpressureDropOldest:
+					for sent := false; !sent; {
+						select {
+						case q <- e:
+							sent = true
+						default:
+							select {
+							case <-q:
+							default:
+							}
+						}
+					}
+				case BackpressureDisconnect:
+					select {
+					case q <- e:
+					default:
+						connected[i] = false
+						close(q)
+					}
+				}
+			}
+		}
+	}()
+	return Readers(outs...)
+}
+
+// BroadcastWith returns a configured Broadcast function closure.
+// Allows creating reusable broadcasters with preset options.
+//
+// Example:
+//
+//	broadcaster := chanz.BroadcastWith[int](OpBuffer(100))
+//	input := chanz.Generate(1, 2, 3)
+//	outputs := broadcaster(input, []chanz.Backpressure{chanz.BackpressureDropNewest, chanz.BackpressureDropNewest})
+func BroadcastWith[A any](options ...Option) func(c <-chan A, policies []Backpressure) []<-chan A {
+	return func(c <-chan A, policies []Backpressure) []<-chan A {
+		return Broadcast(c, policies, options...)
+	}
+}
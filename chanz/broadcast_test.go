@@ -0,0 +1,106 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestBroadcast(t *testing.T) {
+	in := Generate(1, 2, 3, 4, 5)
+	outs := Broadcast(in, []Backpressure{BackpressureBlock, BackpressureBlock, BackpressureBlock})
+	res := collectAll(outs)
+
+	exp := []int{1, 2, 3, 4, 5}
+	for i, r := range res {
+		if !slicez.Equal(exp, r) {
+			t.Logf("expected, %v, but got %v, for output %d", exp, r, i)
+			t.Fail()
+		}
+	}
+}
+
+func TestBroadcastStalledSubscriber(t *testing.T) {
+	in := Generate(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	outs := Broadcast(in, []Backpressure{BackpressureBlock, BackpressureDropNewest, BackpressureDropOldest, BackpressureDisconnect}, OpBuffer(2))
+
+	// Nobody reads the lossy subscribers until the blocking one has seen everything
+	res := Collect(outs[0])
+	exp := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+
+	newest := Collect(outs[1])
+	if len(newest) < 2 || len(newest) > 3 || newest[0] != 1 {
+		t.Errorf("expected drop newest to keep the first elements, got %v", newest)
+	}
+
+	oldest := Collect(outs[2])
+	if len(oldest) < 2 || len(oldest) > 3 || oldest[len(oldest)-1] != 10 {
+		t.Errorf("expected drop oldest to keep the last elements, got %v", oldest)
+	}
+
+	disconnected := Collect(outs[3])
+	if len(disconnected) < 2 || len(disconnected) > 3 || disconnected[0] != 1 {
+		t.Errorf("expected disconnect to deliver what was buffered before disconnecting, got %v", disconnected)
+	}
+}
+
+func TestBroadcastContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 1)
+	in <- 1
+	outs := Broadcast(in, []Backpressure{BackpressureBlock, BackpressureBlock}, OpContext(ctx))
+
+	<-outs[1]
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		collectAll(outs)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("expected outputs to close after cancel")
+	}
+}
+
+func TestFanOutContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 1)
+	in <- 1
+	outs := FanOut(in, 2, OpContext(ctx))
+
+	// outs[1] is never read, so FanOut is stuck sending to it until cancelled
+	<-outs[0]
+	cancel()
+
+	select {
+	case _, ok := <-outs[0]:
+		if ok {
+			t.Error("expected no more elements")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected outputs to close after cancel")
+	}
+}
+
+func TestBroadcastWith(t *testing.T) {
+	broadcaster := BroadcastWith[int](OpBuffer(5))
+	outs := broadcaster(Generate(1, 2, 3), []Backpressure{BackpressureBlock, BackpressureDropNewest})
+	res := collectAll(outs)
+
+	exp := []int{1, 2, 3}
+	for i, r := range res {
+		if !slicez.Equal(exp, r) {
+			t.Logf("expected, %v, but got %v, for output %d", exp, r, i)
+			t.Fail()
+		}
+	}
+}
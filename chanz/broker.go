@@ -0,0 +1,143 @@
+package chanz
+
+import (
+	"strings"
+	"sync"
+)
+
+// Broker is an in-process, topic based, pub/sub broker.
+// Topics are dot separated, e.g. "prices.nasdaq.aapl", and subscriptions may use wildcards,
+// "*" matches exactly one segment and ">" matches one or more trailing segments, e.g. "prices.*.aapl" or "prices.>".
+// Delivery is non-blocking, using WriteIfFree, so a subscriber that has no room in its buffer misses the message
+// instead of stalling the publisher and every other subscriber. Use OpBuffer to give subscribers room.
+type Broker[T any] struct {
+	mu      sync.RWMutex
+	options []Option
+	subs    map[int]*subscription[T]
+	nextID  int
+	closed  bool
+}
+
+type subscription[T any] struct {
+	pattern []string
+	c       chan T
+	write   func(m T)
+	gone    chan struct{}
+}
+
+// NewBroker creates a Broker. The options are used as defaults for every subscription.
+//
+// Example:
+//
+//	broker := chanz.NewBroker[Price](chanz.OpBuffer(100))
+//	defer broker.Close()
+//	prices, unsubscribe := broker.Subscribe("prices.*.aapl")
+//	defer unsubscribe()
+//	broker.Publish("prices.nasdaq.aapl", Price{...})
+func NewBroker[T any](options ...Option) *Broker[T] {
+	return &Broker[T]{
+		options: options,
+		subs:    map[int]*subscription[T]{},
+	}
+}
+
+// Publish delivers msg to every subscription matching topic that has room for it.
+// Publishing to a closed Broker does nothing.
+func (b *Broker[T]) Publish(topic string, msg T) {
+	segments := strings.Split(topic, ".")
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if matchTopic(sub.pattern, segments) {
+			sub.write(msg)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving messages published to topics matching the topic pattern, and a func
+// that unsubscribes and closes the channel. Calling unsubscribe more than once is safe.
+// The options are applied on top of the ones given to NewBroker. The channel has a buffer of buffer size supplied
+// in input Option, default is 0, meaning that messages are only delivered if the subscriber is waiting for one.
+// The subscription ends once the Broker is closed, unsubscribe is called, or the "done" channel or context.Done
+// supplied in Option is closed.
+func (b *Broker[T]) Subscribe(topic string, options ...Option) (<-chan T, func()) {
+	var s settings
+	for _, o := range append(append([]Option{}, b.options...), options...) {
+		s = o(s)
+	}
+
+	c := make(chan T, s.buffer)
+	sub := &subscription[T]{
+		pattern: strings.Split(topic, "."),
+		c:       c,
+		write:   WriteTo[T](c, WriteIfFree),
+		gone:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(c)
+		return c, func() {}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[id]; !ok {
+			return
+		}
+		delete(b.subs, id)
+		close(sub.gone)
+		close(sub.c)
+	}
+
+	if s.done != nil {
+		s.started()
+		go func() {
+			defer s.finished(nil)
+			select {
+			case <-s.done:
+				unsubscribe()
+			case <-sub.gone:
+			}
+		}()
+	}
+	return c, unsubscribe
+}
+
+// Close ends every subscription, closing their channels. Later subscriptions are closed right away.
+func (b *Broker[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, sub := range b.subs {
+		delete(b.subs, id)
+		close(sub.gone)
+		close(sub.c)
+	}
+}
+
+// matchTopic reports if the topic segments are matched by the, possibly wildcarded, pattern segments
+func matchTopic(pattern []string, topic []string) bool {
+	for i, p := range pattern {
+		if p == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) {
+			return false
+		}
+		if p != "*" && p != topic[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
@@ -0,0 +1,119 @@
+package chanz
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker[string](OpBuffer(10))
+
+	exact, _ := broker.Subscribe("prices.nasdaq.aapl")
+	single, _ := broker.Subscribe("prices.*.aapl")
+	trailing, _ := broker.Subscribe("prices.>")
+	other, _ := broker.Subscribe("orders.>")
+
+	broker.Publish("prices.nasdaq.aapl", "a")
+	broker.Publish("prices.lse.aapl", "b")
+	broker.Publish("prices.nasdaq.msft", "c")
+	broker.Publish("prices", "d")
+	broker.Close()
+
+	for _, tc := range []struct {
+		c   <-chan string
+		exp []string
+	}{
+		{exact, []string{"a"}},
+		{single, []string{"a", "b"}},
+		{trailing, []string{"a", "b", "c"}},
+		{other, nil},
+	} {
+		res := Collect(tc.c)
+		if !slicez.Equal(tc.exp, res) {
+			t.Logf("expected, %v, but got %v", tc.exp, res)
+			t.Fail()
+		}
+	}
+}
+
+func TestBrokerUnsubscribe(t *testing.T) {
+	broker := NewBroker[int]()
+	defer broker.Close()
+
+	c, unsubscribe := broker.Subscribe("a", OpBuffer(1))
+	broker.Publish("a", 1)
+	unsubscribe()
+	unsubscribe()
+	broker.Publish("a", 2)
+
+	res := Collect(c)
+	exp := []int{1}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestBrokerContext(t *testing.T) {
+	broker := NewBroker[int](OpBuffer(1))
+	defer broker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := broker.Subscribe("a", OpContext(ctx))
+	cancel()
+	expectClosed(t, c)
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	broker := NewBroker[int]()
+	defer broker.Close()
+
+	slow, _ := broker.Subscribe("a", OpBuffer(1))
+	fast, _ := broker.Subscribe("a", OpBuffer(3))
+	for i := 1; i <= 3; i++ {
+		broker.Publish("a", i)
+	}
+
+	if res := TakeBuffer(slow); !slicez.Equal([]int{1}, res) {
+		t.Errorf("expected slow subscriber to miss messages, got %v", res)
+	}
+	if res := TakeBuffer(fast); !slicez.Equal([]int{1, 2, 3}, res) {
+		t.Errorf("expected fast subscriber to get all messages, got %v", res)
+	}
+}
+
+func TestBrokerClosed(t *testing.T) {
+	broker := NewBroker[int]()
+	broker.Close()
+	broker.Close()
+
+	c, unsubscribe := broker.Subscribe("a")
+	broker.Publish("a", 1)
+	unsubscribe()
+	expectClosed(t, c)
+}
+
+func TestMatchTopic(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, topic string
+		exp            bool
+	}{
+		{"a.b.c", "a.b.c", true},
+		{"a.b.c", "a.b", false},
+		{"a.b", "a.b.c", false},
+		{"a.*.c", "a.b.c", true},
+		{"a.*", "a.b.c", false},
+		{"a.>", "a.b.c", true},
+		{"a.>", "a", false},
+		{">", "a", true},
+		{"*", "a", true},
+		{"*.*", "a", false},
+	} {
+		if res := matchTopic(strings.Split(tc.pattern, "."), strings.Split(tc.topic, ".")); res != tc.exp {
+			t.Errorf("expected %v for %s matching %s, got %v", tc.exp, tc.pattern, tc.topic, res)
+		}
+	}
+}
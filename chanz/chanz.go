@@ -1,19 +1,44 @@
 // Package chanz provides utility functions for working with Go channels.
 //
 // The package offers functional-style operations on channels including:
-//   - Transformation: Map, Flatten, Zip/Unzip
+//   - Transformation: Map, ParallelMap, Flatten, Zip/Unzip, JoinByKey, CombineLatest, WithLatestFrom
 //   - Filtering: Filter, Compact, Take/Drop variants
-//   - Aggregation: FanIn, FanOut, Concat
+//   - Aggregation: FanIn, Select, Multiplexer, FanOut, Broadcast, Distribute, Concat, MergeSorted, Fold, Scan, FoldByKey
 //   - Generation: Generate, Generator
-//   - Utilities: Collect, Partition, Done signal handling
+//   - Context aware: MapCtx, FilterCtx, PeekCtx, GeneratorCtx
+//   - Batching: Batch, Buffer
+//   - Rate limiting: Throttle, Debounce, Sample
+//   - Liveness: Timeout, TimeoutClose, Heartbeat
+//   - Time sources: Interval, After, Schedule
+//   - Pub/sub: Broker
+//   - Error handling: MapErr, MapRetry, SplitResults, CollectResults
+//   - Routing: Partition, Route, RouteBy
+//   - Futures: Go, AllOf, AnyOf, Race, with Await and ToChan
+//   - I/O: Lines, ScanReader, DecodeJSONL sources and WriteLines, EncodeJSONL sinks
+//   - Utilities: Collect, Done signal handling
 //
 // Most functions support functional options for configuration:
 //   - OpBuffer(n): Set channel buffer size (default 0)
 //   - OpContext(ctx): Stop when context is cancelled
 //   - OpDone(ch): Stop when done channel is closed
+//   - OpOrdered(): Preserve input order in concurrent stages
+//   - OpFailFast(cancel): Stop at the first error in error aware stages
+//   - OpClock(clock): Source of time for time based stages, default is the wall clock
+//   - OpJoin(mode): What JoinByKey does with unmatched elements
+//   - OpRecover(handler), OpRecoverStop(handler): Recover from panics in stage functions
+//   - OpObserver(name, observer): Report per stage events, such as received, emitted and blocked, to observer
+//   - OpShutdown(mode): Abort right away, the default, or drain in-flight elements once told to stop
+//   - OpGroup(group): Track stages in a Group, to wait for a pipeline to quiesce
+//   - OpTracker(tracker), OpName(name): Record running stages, with name and creation stack, see also SetTracker
 //
 // Functions ending in "With" (e.g., MapWith) return closures that can be reused
 // with the same options, useful for pipeline building.
+// Stream wraps a chan in a fluent API built on these closures, sharing one set of options between all stages:
+//
+//	result := chanz.StreamOf(input, chanz.OpBuffer(10)).
+//	    Filter(func(n int) bool { return n%2 == 0 }).
+//	    Take(3).
+//	    Collect()
 //
 // Example pipeline:
 //
@@ -34,8 +59,30 @@ import (
 // settings holds configuration for channel operations.
 // Used internally with the Option pattern.
 type settings struct {
-	done   <-chan struct{} // Signal to stop processing
-	buffer int             // Channel buffer size
+	done    <-chan struct{} // Signal to stop processing
+	ctx     context.Context // Context supplied with OpContext, passed on by the Ctx variants of stages
+	buffer  int             // Channel buffer size
+	ordered bool            // Preserve input order in concurrent stages
+
+	failFast bool   // Stop at the first error in error aware stages
+	cancel   func() // Called on the first error when failing fast
+
+	clock Clock    // Source of time for time based stages
+	join  JoinMode // What to do with unmatched elements in JoinByKey
+
+	recover     bool        // Recover from panics in stage functions
+	recoverStop bool        // Stop the stage, instead of skipping the element, after a panic
+	onPanic     func(p any) // Called with recovered panics
+
+	name     string   // Name of the stage reported to the observer
+	observer Observer // Receives events from the stage
+
+	drain bool            // Only stop sources on done, other stages run until their input is closed
+	abort <-chan struct{} // Stops a stage regardless of shutdown mode
+	group *Group          // Keeps track of the stage until it stops
+
+	tracker *Tracker // Records the stage while it runs, instead of the tracker set with SetTracker
+	tracked *tracked // Set by started if the stage is recorded by a tracker
 }
 
 // Option is a functional option for configuring channel operations.
@@ -47,6 +94,7 @@ type Option func(s settings) settings
 func OpContext(ctx context.Context) Option {
 	return func(s settings) settings {
 		s.done = SomeDone(ctx.Done(), s.done)
+		s.ctx = ctx
 		return s
 	}
 }
@@ -79,13 +127,23 @@ func Map[A any, B any](in <-chan A, mapper func(a A) B, options ...Option) <-cha
 	}
 
 	out := make(chan B, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
 		for e := range in {
-			select {
-			case <-s.done:
+			s.observe(EventReceived, 0)
+			b, ok := call(s, mapper, e)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, out, b) {
 				return
-			case out <- mapper(e):
 			}
 		}
 	}()
@@ -143,18 +201,14 @@ func Flatten[A any](in <-chan []A, options ...Option) <-chan A {
 	}
 
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() { close(out) })
 		for slice := range in {
-			if len(slice) == 0 {
-				continue
-			}
-			select {
-			case <-s.done:
-				return
-			case out <- slice[0]:
-				for _, e := range slice[1:] {
-					out <- e
+			s.observe(EventReceived, 0)
+			for _, e := range slice {
+				if !send(s, out, e) {
+					return
 				}
 			}
 		}
@@ -199,18 +253,16 @@ func Generator[A any](gen func(func(A)), options ...Option) <-chan A {
 	for _, o := range options {
 		s = o(s)
 	}
+	s = s.source()
 	out := make(chan A, s.buffer)
 
 	yield := func(a A) {
-		select {
-		case <-s.done:
-			return
-		case out <- a:
-		}
+		send(s, out, a)
 	}
+	s.started()
 	go func() {
-		defer close(out)
-		gen(yield)
+		defer s.finished(func() { close(out) })
+		try(s, func() { gen(yield) })
 	}()
 	return out
 }
@@ -255,14 +307,14 @@ func GenerateWith[A any](options ...Option) func(elements ...A) <-chan A {
 		for _, o := range options {
 			s = o(s)
 		}
+		s = s.source()
 		out := make(chan A, s.buffer)
+		s.started()
 		go func() {
-			defer close(out)
+			defer s.finished(func() { close(out) })
 			for _, e := range elements {
-				select {
-				case <-s.done:
+				if !send(s, out, e) {
 					return
-				case out <- e:
 				}
 			}
 		}()
@@ -301,21 +353,20 @@ func FanInWith[A any](options ...Option) func(cs ...<-chan A) <-chan A {
 		output := func(c <-chan A) {
 			defer wg.Done()
 			for e := range c {
-				select {
-				case <-s.done:
+				s.observe(EventReceived, 0)
+				if !send(s, out, e) {
 					return
-				case out <- e:
 				}
 			}
 		}
+		s.started()
 		wg.Add(len(cs))
 		for _, c := range cs {
 			go output(c)
 		}
-
 		go func() {
 			wg.Wait()
-			close(out)
+			s.finished(func() { close(out) })
 		}()
 		return out
 	}
@@ -324,7 +375,8 @@ func FanInWith[A any](options ...Option) func(cs ...<-chan A) <-chan A {
 // FanOut splits one input channel into multiple output channels.
 // Each value from the input is sent to all output channels (broadcast pattern).
 // A value won't be read from input until all outputs have consumed the previous value
-// (if buffers are full). Output channels are closed when input closes.
+// (if buffers are full), so the slowest consumer sets the pace. Use Broadcast to decouple consumers.
+// Output channels are closed when input closes.
 //
 // Example:
 //
@@ -344,20 +396,19 @@ func FanOut[A any](c <-chan A, size int, options ...Option) []<-chan A {
 		outs[i] = make(chan A, s.buffer)
 	}
 
+	s.started()
 	go func() {
-		defer func() {
+		defer s.finished(func() {
 			for _, o := range outs {
 				close(o)
 			}
-		}()
+		})
 
 		for e := range c {
-			select {
-			case <-s.done:
-				return
-			case outs[0] <- e: // Might want to do this concurrently somehow?
-				for _, o := range outs[1:] {
-					o <- e
+			s.observe(EventReceived, 0)
+			for _, o := range outs {
+				if !send(s, o, e) {
+					return
 				}
 			}
 		}
@@ -407,14 +458,14 @@ func ConcatWith[A any](options ...Option) func(cs ...<-chan A) <-chan A {
 		}
 
 		out := make(chan A, s.buffer)
+		s.started()
 		go func() {
-			defer close(out)
+			defer s.finished(func() { close(out) })
 			for _, c := range cs {
 				for e := range c {
-					select {
-					case <-s.done:
+					s.observe(EventReceived, 0)
+					if !send(s, out, e) {
 						return
-					case out <- e:
 					}
 				}
 			}
@@ -433,16 +484,23 @@ func Filter[A any](c <-chan A, include func(a A) bool, options ...Option) <-chan
 	}
 
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
 		for e := range c {
-			if !include(e) {
+			s.observe(EventReceived, 0)
+			keep, ok := call(s, include, e)
+			if !ok && s.recoverStop {
+				return
+			}
+			if !keep {
 				continue
 			}
-			select {
-			case <-s.done:
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 		}
 	}()
@@ -474,28 +532,33 @@ func Compact[A any](c <-chan A, equal func(a, b A) bool, options ...Option) <-ch
 		s = o(s)
 	}
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
 
 		var a, ok = <-c
 		if !ok {
 			return
 		}
-		select {
-		case <-s.done:
+		if !send(s, out, a) {
 			return
-		case out <- a:
 		}
 
 		for b := range c {
-			if equal(a, b) {
+			s.observe(EventReceived, 0)
+			same, ok := call(s, func(b A) bool { return equal(a, b) }, b)
+			if !ok && s.recoverStop {
+				return
+			}
+			if same || !ok {
 				continue
 			}
 			a = b
-			select {
-			case <-s.done:
+			if !send(s, out, b) {
 				return
-			case out <- b:
 			}
 		}
 	}()
@@ -530,19 +593,29 @@ func Partition[A any](c <-chan A, predicate func(a A) bool, options ...Option) (
 
 	sat := make(chan A, s.buffer)
 	not := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(sat)
-		defer close(not)
+		defer s.finished(func() {
+			close(not)
+			close(sat)
+			discard(s, c)
+		})
 
 		for e := range c {
+			s.observe(EventReceived, 0)
+			satisfies, ok := call(s, predicate, e)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
 			out := sat
-			if !predicate(e) {
+			if !satisfies {
 				out = not
 			}
-			select {
-			case <-s.done:
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 		}
 	}()
@@ -573,16 +646,23 @@ func TakeWhile[A any](c <-chan A, take func(a A) bool, options ...Option) <-chan
 		s = o(s)
 	}
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
 		for e := range c {
-			if !take(e) {
+			s.observe(EventReceived, 0)
+			keep, ok := call(s, take, e)
+			if !ok && !s.recoverStop {
+				continue
+			}
+			if !keep {
 				return
 			}
-			select {
-			case <-s.done:
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 
 		}
@@ -614,17 +694,20 @@ func Take[A any](c <-chan A, i int, options ...Option) <-chan A {
 		s = o(s)
 	}
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
 		if i < 1 {
 			return
 		}
 
 		for e := range c {
-			select {
-			case <-s.done:
+			s.observe(EventReceived, 0)
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 			i -= 1
 			if i == 0 {
@@ -660,18 +743,18 @@ func Drop[A any](c <-chan A, i int, options ...Option) <-chan A {
 	}
 
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() { close(out) })
 		for e := range c {
+			s.observe(EventReceived, 0)
 			if i > 0 {
 				i -= 1
 				continue
 			}
 
-			select {
-			case <-s.done:
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 
 		}
@@ -704,18 +787,27 @@ func DropWhile[A any](c <-chan A, drop func(a A) bool, options ...Option) <-chan
 	}
 
 	out := make(chan A, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
 		var dropping = true
 		for e := range c {
-			if dropping && drop(e) {
-				continue
+			s.observe(EventReceived, 0)
+			if dropping {
+				skip, ok := call(s, drop, e)
+				if !ok && s.recoverStop {
+					return
+				}
+				if skip || !ok {
+					continue
+				}
 			}
 			dropping = false
-			select {
-			case <-s.done:
+			if !send(s, out, e) {
 				return
-			case out <- e:
 			}
 		}
 	}()
@@ -747,17 +839,29 @@ func Zip[A any, B any, C any](ac <-chan A, bc <-chan B, zipper func(a A, b B) C,
 	}
 
 	out := make(chan C, s.buffer)
+	s.started()
 	go func() {
-		defer close(out)
+		defer s.finished(func() {
+			close(out)
+			discard(s, ac)
+			discard(s, bc)
+		})
 		for a := range ac {
+			s.observe(EventReceived, 0)
 			b, ok := <-bc
 			if !ok {
 				return
 			}
-			select {
-			case <-s.done:
+			s.observe(EventReceived, 0)
+			c, ok := call(s, func(b B) C { return zipper(a, b) }, b)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, out, c) {
 				return
-			case out <- zipper(a, b):
 			}
 		}
 	}()
@@ -775,16 +879,25 @@ func Unzip[A any, B any, C any](zipped <-chan C, unzipper func(c C) (A, B), opti
 
 	ac := make(chan A, s.buffer)
 	bc := make(chan B, s.buffer)
+	s.started()
 	go func() {
-		defer close(ac)
-		defer close(bc)
+		defer s.finished(func() {
+			close(bc)
+			close(ac)
+			discard(s, zipped)
+		})
 		for c := range zipped {
-			a, b := unzipper(c)
-			select {
-			case <-s.done:
+			s.observe(EventReceived, 0)
+			var a A
+			var b B
+			if !try(s, func() { a, b = unzipper(c) }) {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, ac, a) || !send(s, bc, b) {
 				return
-			case ac <- a:
-				bc <- b
 			}
 		}
 	}()
@@ -802,7 +915,7 @@ func Collect[A any](c <-chan A, options ...Option) []A {
 	for val := range c {
 		out = append(out, val)
 		select {
-		case <-s.done:
+		case <-s.stop():
 			return out
 		default:
 		}
@@ -921,7 +1034,7 @@ func Buffer[A any](size int, in <-chan A, options ...Option) ([]A, bool) {
 			return out, true
 		}
 		select {
-		case <-s.done:
+		case <-s.stop():
 			return out, true
 		default:
 		}
@@ -935,3 +935,39 @@ func TestCompact_EmptyChannel(t *testing.T) {
 		t.Errorf("Compact on empty channel should return empty, got %v", res)
 	}
 }
+
+// expectNothing fails if c yields anything within a short while
+func expectNothing[A any](t *testing.T, c <-chan A) {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		t.Fatalf("expected nothing, got %v, %v", e, ok)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// expectNext fails if c does not yield exp within a second
+func expectNext[A comparable](t *testing.T, c <-chan A, exp A) {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		if !ok || e != exp {
+			t.Fatalf("expected %v, got %v, %v", exp, e, ok)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected %v, got nothing", exp)
+	}
+}
+
+// expectClosed fails if c is not closed within a second
+func expectClosed[A any](t *testing.T, c <-chan A) {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		if ok {
+			t.Fatalf("expected channel to be closed, got %v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected channel to be closed")
+	}
+}
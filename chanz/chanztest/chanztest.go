@@ -0,0 +1,101 @@
+// Package chanztest provides helpers for testing chanz pipelines deterministically, without real sleeps.
+//
+// It offers:
+//   - FakeClock: a chanz.Clock where time only moves when told to, supply it with chanz.OpClock
+//   - Source: a controllable input chan that reports backpressure
+//   - VerifyNoLeaks: fail a test if chanz goroutines outlive it
+//   - ExpectSequence, ExpectNothing, ExpectClosed: assert what a chan emits, with a timeout
+//
+// Example:
+//
+//	func TestDebounce(t *testing.T) {
+//	    chanztest.VerifyNoLeaks(t)
+//	    clock := chanztest.NewFakeClock(time.Time{})
+//	    in := chanztest.NewSource[int](t, 0)
+//	    out := chanz.Debounce(in.C(), time.Second, chanz.OpClock(clock))
+//
+//	    in.Send(1, 2)
+//	    clock.BlockUntilArmed(t, 2) // a timer for every element
+//	    clock.Advance(time.Second)
+//	    chanztest.ExpectSequence(t, out, time.Second, 2)
+//	    in.Close()
+//	    chanztest.ExpectClosed(t, out, time.Second)
+//	}
+package chanztest
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	// DefaultTimeout is how long helpers wait for something that is expected to happen, such as a Source being
+	// read or a timer being armed, before failing the test.
+	DefaultTimeout = time.Second
+	// ShortWait is how long helpers wait for something that is expected not to happen, such as a Source being read
+	// while a stage applies backpressure.
+	ShortWait = 20 * time.Millisecond
+)
+
+// ExpectSequence fails t unless c emits exactly want, in order, within timeout. It does not check that c is closed
+// afterwards, use ExpectClosed or ExpectNothing for that.
+//
+// Example:
+//
+//	chanztest.ExpectSequence(t, chanz.Map(chanz.Generate(1, 2, 3), double), time.Second, 2, 4, 6)
+func ExpectSequence[T comparable](t testing.TB, c <-chan T, timeout time.Duration, want ...T) {
+	t.Helper()
+	ExpectSequenceFunc(t, c, timeout, func(a, b T) bool { return a == b }, want...)
+}
+
+// ExpectSequenceFunc is like ExpectSequence, but compares elements using equal, for element types that are not
+// comparable.
+//
+// Example:
+//
+//	chanztest.ExpectSequenceFunc(t, batches, time.Second, slicez.Equal[int], []int{1, 2}, []int{3})
+func ExpectSequenceFunc[T any](t testing.TB, c <-chan T, timeout time.Duration, equal func(a, b T) bool, want ...T) {
+	t.Helper()
+	deadline := time.After(timeout)
+	var got []T
+	for i, w := range want {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				t.Fatalf("expected %v, but the chan was closed after %v", want, got)
+			}
+			got = append(got, e)
+			if !equal(e, w) {
+				t.Fatalf("expected %v, but got %v at index %d, after %v", w, e, i, got[:i])
+			}
+		case <-deadline:
+			t.Fatalf("expected %v within %v, but only got %v", want, timeout, got)
+		}
+	}
+}
+
+// ExpectNothing fails t if c emits an element, or is closed, within d.
+func ExpectNothing[T any](t testing.TB, c <-chan T, d time.Duration) {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		if !ok {
+			t.Fatal("expected nothing, but the chan was closed")
+		}
+		t.Fatalf("expected nothing, but got %v", e)
+	case <-time.After(d):
+	}
+}
+
+// ExpectClosed fails t unless c is closed within timeout, without emitting any more elements.
+func ExpectClosed[T any](t testing.TB, c <-chan T, timeout time.Duration) {
+	t.Helper()
+	select {
+	case e, ok := <-c:
+		if ok {
+			t.Fatalf("expected the chan to be closed, but got %v", e)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("expected the chan to be closed within %v", timeout)
+	}
+}
@@ -0,0 +1,137 @@
+package chanztest
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/slicez"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test, Fatal stops the calling goroutine
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	failures []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.Errorf("%s", fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record calls f with a recorder in a new goroutine, so that Fatal can stop it, runs the cleanups and returns the
+// failures
+func record(t *testing.T, f func(tb testing.TB)) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(r)
+	}()
+	<-done
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	return r.failures
+}
+
+func TestExpectSequence(t *testing.T) {
+	ExpectSequence(t, chanz.Generate(1, 2, 3), time.Second, 1, 2, 3)
+
+	failures := record(t, func(tb testing.TB) {
+		ExpectSequence(tb, chanz.Generate(1, 5, 3), time.Second, 1, 2, 3)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a wrong element to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		ExpectSequence(tb, chanz.Generate(1), time.Second, 1, 2)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a closed chan to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		ExpectSequence(tb, make(chan int), 10*time.Millisecond, 1)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a timeout to fail, but got %v", failures)
+		t.Fail()
+	}
+}
+
+func TestExpectSequenceFunc(t *testing.T) {
+	batches := chanz.Batch(chanz.Generate(1, 2, 3), 2, time.Hour)
+	ExpectSequenceFunc(t, batches, time.Second, slicez.Equal[int], []int{1, 2}, []int{3})
+}
+
+func TestExpectNothing(t *testing.T) {
+	ExpectNothing(t, make(chan int), ShortWait)
+
+	failures := record(t, func(tb testing.TB) {
+		ExpectNothing(tb, chanz.Generate(1), time.Second)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, an element to fail, but got %v", failures)
+		t.Fail()
+	}
+}
+
+func TestExpectClosed(t *testing.T) {
+	ExpectClosed(t, chanz.Generate[int](), time.Second)
+
+	failures := record(t, func(tb testing.TB) {
+		ExpectClosed(tb, chanz.Generate(1), time.Second)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, an element to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		ExpectClosed(tb, make(chan int), 10*time.Millisecond)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, an open chan to fail, but got %v", failures)
+		t.Fail()
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	VerifyNoLeaks(t)
+	clock := NewFakeClock(time.Time{})
+	in := NewSource[int](t, 0)
+	out := chanz.Debounce(in.C(), time.Second, chanz.OpClock(clock))
+
+	in.Send(1, 2)
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(time.Second)
+	ExpectSequence(t, out, time.Second, 2)
+	in.Close()
+	ExpectClosed(t, out, time.Second)
+}
@@ -0,0 +1,204 @@
+package chanztest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+)
+
+// FakeClock is a chanz.Clock where time only moves when Advance is called. Supply it to time based stages with
+// chanz.OpClock to test them without sleeping.
+//
+// Example:
+//
+//	clock := chanztest.NewFakeClock(time.Time{})
+//	out := chanz.Debounce(in.C(), time.Second, chanz.OpClock(clock))
+//	in.Send(1)
+//	clock.BlockUntil(t, 1) // the stage has armed its timer
+//	clock.Advance(time.Second)
+//	chanztest.ExpectSequence(t, out, time.Second, 1)
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	armed  chan struct{} // closed, and replaced, whenever a timer is armed
+	total  int           // number of times a timer has been armed
+}
+
+// NewFakeClock creates a FakeClock starting at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, armed: make(chan struct{})}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a timer that fires once the clock has been advanced by d. A d <= 0 fires right away.
+func (c *FakeClock) NewTimer(d time.Duration) chanz.Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker creates a ticker that fires every time the clock has been advanced by d. It panics if d <= 0.
+func (c *FakeClock) NewTicker(d time.Duration) chanz.Ticker {
+	if d <= 0 {
+		panic("chanztest: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), period: d}
+	t.Reset(d)
+	return fakeTicker{t}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker that expires on the way, in order.
+// Like the ones of the time package, a ticker that has not been read since it last fired drops the tick.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	end := c.now.Add(d)
+	for {
+		next := c.next()
+		if next == nil || next.at.After(end) {
+			break
+		}
+		c.now = next.at
+		next.fire()
+	}
+	c.now = end
+}
+
+// Waiters returns the number of timers and tickers that have not yet fired or been stopped.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers and tickers are waiting on the clock, which tells that a stage has
+// reached the point where it waits for time to pass. It fails t if that does not happen within DefaultTimeout.
+func (c *FakeClock) BlockUntil(t testing.TB, n int) {
+	t.Helper()
+	c.wait(t, func() int { return len(c.timers) }, n, "timers to be waiting on the clock")
+}
+
+// BlockUntilArmed waits until timers and tickers have been created, or reset, at least n times in total since the
+// clock was created. Unlike BlockUntil, it tells a stage that replaces its timer, e.g. for every element, apart from
+// one that has not yet done so. It fails t if that does not happen within DefaultTimeout.
+//
+// Example:
+//
+//	out := chanz.TimeoutClose(in.C(), time.Minute, chanz.OpClock(clock)) // arms a timer
+//	in.Send(1)
+//	chanztest.ExpectSequence(t, out, time.Second, 1)
+//	clock.BlockUntilArmed(t, 2) // the timer has been replaced after the element
+//	clock.Advance(time.Minute)
+func (c *FakeClock) BlockUntilArmed(t testing.TB, n int) {
+	t.Helper()
+	c.wait(t, func() int { return c.total }, n, "timers to have been armed")
+}
+
+// wait waits until count, called with the clock locked, returns at least n
+func (c *FakeClock) wait(t testing.TB, count func() int, n int, what string) {
+	t.Helper()
+	deadline := time.After(DefaultTimeout)
+	for {
+		c.mu.Lock()
+		got, armed := count(), c.armed
+		c.mu.Unlock()
+		if got >= n {
+			return
+		}
+		select {
+		case <-armed:
+		case <-deadline:
+			t.Fatalf("expected %d %s, got %d", n, what, got)
+		}
+	}
+}
+
+// next returns the timer that expires first, or nil if there is none
+func (c *FakeClock) next() *fakeTimer {
+	var next *fakeTimer
+	for _, t := range c.timers {
+		if next == nil || t.at.Before(next.at) {
+			next = t
+		}
+	}
+	return next
+}
+
+// arm counts a timer being armed, whether it waits or fires right away, and wakes up BlockUntil and BlockUntilArmed
+func (c *FakeClock) arm() {
+	c.total++
+	close(c.armed)
+	c.armed = make(chan struct{})
+}
+
+// remove stops waiting on t, it returns false if t was not waiting
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, w := range c.timers {
+		if w == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	waiting := t.clock.remove(t)
+	t.at = t.clock.now.Add(d)
+	if d <= 0 && t.period == 0 {
+		t.fire()
+	} else {
+		t.clock.timers = append(t.clock.timers, t)
+	}
+	t.clock.arm()
+	return waiting
+}
+
+// fire sends the time the timer expired at, and either rearms a ticker or stops a timer, the clock must be locked
+func (t *fakeTimer) fire() {
+	select {
+	case t.c <- t.at:
+	default:
+	}
+	if t.period > 0 {
+		t.at = t.at.Add(t.period)
+		return
+	}
+	t.clock.remove(t)
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
@@ -0,0 +1,133 @@
+package chanztest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+)
+
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockTimer(t *testing.T) {
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+	if clock.Waiters() != 1 {
+		t.Logf("expected, 1, but got %v", clock.Waiters())
+		t.Fail()
+	}
+
+	clock.Advance(999 * time.Millisecond)
+	ExpectNothing(t, timer.C(), ShortWait)
+	clock.Advance(time.Millisecond)
+	ExpectSequence(t, timer.C(), time.Second, start.Add(time.Second))
+	if clock.Waiters() != 0 || !clock.Now().Equal(start.Add(time.Second)) {
+		t.Logf("expected, no waiters at %v, but got %v at %v", start.Add(time.Second), clock.Waiters(), clock.Now())
+		t.Fail()
+	}
+
+	if timer.Reset(time.Second) {
+		t.Log("expected, Reset of a fired timer to return false")
+		t.Fail()
+	}
+	if !timer.Stop() {
+		t.Log("expected, Stop of a waiting timer to return true")
+		t.Fail()
+	}
+	clock.Advance(time.Hour)
+	ExpectNothing(t, timer.C(), ShortWait)
+
+	ExpectSequence(t, clock.NewTimer(0).C(), time.Second, clock.Now())
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	clock := NewFakeClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(time.Second)
+	ExpectSequence(t, ticker.C(), time.Second, start.Add(time.Second))
+
+	clock.Advance(3 * time.Second) // an unread ticker drops ticks
+	ExpectSequence(t, ticker.C(), time.Second, start.Add(2*time.Second))
+	ExpectNothing(t, ticker.C(), ShortWait)
+
+	ticker.Stop()
+	clock.Advance(time.Second)
+	ExpectNothing(t, ticker.C(), ShortWait)
+}
+
+func TestFakeClockOrder(t *testing.T) {
+	clock := NewFakeClock(start)
+	var fired []time.Time
+	late := clock.NewTimer(2 * time.Second)
+	early := clock.NewTimer(time.Second)
+
+	clock.Advance(time.Hour)
+	fired = append(fired, <-early.C(), <-late.C())
+	if !fired[0].Equal(start.Add(time.Second)) || !fired[1].Equal(start.Add(2*time.Second)) {
+		t.Logf("expected, timers to fire at their own time, but got %v", fired)
+		t.Fail()
+	}
+}
+
+func TestFakeClockStage(t *testing.T) {
+	clock := NewFakeClock(start)
+	in := NewSource[int](t, 0)
+	out := chanz.TimeoutClose(in.C(), time.Minute, chanz.OpClock(clock))
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(30 * time.Second)
+	in.Send(1)
+	ExpectSequence(t, out, time.Second, 1)
+
+	clock.BlockUntilArmed(t, 2) // the timer is replaced after every element
+	clock.Advance(59 * time.Second)
+	ExpectNothing(t, out, ShortWait)
+	clock.Advance(time.Second)
+	ExpectClosed(t, out, time.Second)
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	defer func(d time.Duration) { DefaultTimeout = d }(DefaultTimeout)
+	DefaultTimeout = 10 * time.Millisecond
+
+	clock := NewFakeClock(start)
+	failures := record(t, func(tb testing.TB) {
+		clock.BlockUntil(tb, 1)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, no timer to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	timer := clock.NewTimer(time.Second)
+	clock.BlockUntil(t, 1)
+	timer.Stop()
+	failures = record(t, func(tb testing.TB) {
+		clock.BlockUntilArmed(tb, 2)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a single armed timer to fail, but got %v", failures)
+		t.Fail()
+	}
+	timer.Reset(time.Second)
+	clock.BlockUntilArmed(t, 2)
+}
+
+func TestFakeClockBlockUntilArmedFired(t *testing.T) {
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+
+	go func() {
+		time.Sleep(ShortWait) // let BlockUntilArmed wait before the timer is armed again
+		timer.Reset(0)        // fires right away, without waiting on the clock
+	}()
+	failures := record(t, func(tb testing.TB) {
+		clock.BlockUntilArmed(tb, 2)
+	})
+	if len(failures) != 0 {
+		t.Logf("expected, a timer that fires right away to wake BlockUntilArmed, but got %v", failures)
+		t.Fail()
+	}
+	ExpectSequence(t, timer.C(), time.Second, start)
+}
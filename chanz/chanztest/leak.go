@@ -0,0 +1,112 @@
+package chanztest
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanzFrame is the prefix of the stack frames of functions in the chanz package. Frames of sub packages, such as
+// this one, continue with a "/" rather than a "."
+const chanzFrame = "github.com/modfin/henry/chanz."
+
+// VerifyNoLeaks fails t if a goroutine running chanz code, e.g. the goroutine of a stage, that was started after
+// VerifyNoLeaks is called is still running once the test, and its deferred calls, have finished.
+// Stages stop asynchronously, so they are given up to DefaultTimeout to do so.
+// A stage leaks when its output is not drained and no done channel or context is supplied to stop it, and
+// cancelling the context supplied with chanz.OpContext in a deferred call is enough to stop the stages using it.
+//
+// Example:
+//
+//	func TestPipeline(t *testing.T) {
+//	    chanztest.VerifyNoLeaks(t)
+//	    ctx, cancel := context.WithCancel(context.Background())
+//	    defer cancel()
+//	    in := chanz.GenerateWith[int](chanz.OpContext(ctx))(1, 2, 3)
+//	    out := chanz.Map(in, double, chanz.OpContext(ctx))
+//	    chanztest.ExpectSequence(t, out, time.Second, 2) // the rest is never read, but cancel stops the stages
+//	}
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := map[string]bool{}
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	t.Cleanup(func() {
+		var leaked []goroutine
+		deadline := time.Now().Add(DefaultTimeout)
+		for {
+			leaked = leaked[:0]
+			for _, g := range goroutines() {
+				if !before[g.id] && g.chanz() {
+					leaked = append(leaked, g)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		for _, g := range leaked {
+			t.Errorf("leaked goroutine:\n%s", g.stack)
+		}
+	})
+}
+
+// Stages returns the stacks of all goroutines currently running chanz code, which is useful to find out what a
+// pipeline is waiting on.
+func Stages() []string {
+	var stacks []string
+	for _, g := range goroutines() {
+		if g.chanz() {
+			stacks = append(stacks, g.stack)
+		}
+	}
+	return stacks
+}
+
+type goroutine struct {
+	id    string
+	stack string
+}
+
+// chanz returns true if the goroutine is running, or was started by, chanz code
+func (g goroutine) chanz() bool {
+	for _, line := range strings.Split(g.stack, "\n") {
+		line = strings.TrimPrefix(line, "created by ")
+		if strings.HasPrefix(line, chanzFrame) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns all goroutines, except the calling one
+func goroutines() []goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var gs []goroutine
+	for i, stack := range strings.Split(string(buf), "\n\n") {
+		if i == 0 {
+			continue // the first goroutine is the calling one
+		}
+		// The first line is "goroutine 17 [chan send]:"
+		header := strings.Fields(strings.SplitN(stack, "\n", 2)[0])
+		if len(header) < 2 || header[0] != "goroutine" {
+			continue
+		}
+		gs = append(gs, goroutine{id: header[1], stack: stack})
+	}
+	return gs
+}
@@ -0,0 +1,45 @@
+package chanztest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+)
+
+func TestVerifyNoLeaks(t *testing.T) {
+	VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := chanz.GenerateWith[int](chanz.OpContext(ctx))(1, 2, 3)
+	out := chanz.Map(in, func(i int) int { return i }, chanz.OpContext(ctx))
+	ExpectSequence(t, out, time.Second, 1) // the rest is never read, but cancel stops the stages
+}
+
+func TestVerifyNoLeaksLeak(t *testing.T) {
+	defer func(d time.Duration) { DefaultTimeout = d }(DefaultTimeout)
+	DefaultTimeout = 50 * time.Millisecond
+
+	in := make(chan int)
+	var out <-chan int
+	failures := record(t, func(tb testing.TB) {
+		VerifyNoLeaks(tb)
+		out = chanz.Map(in, func(i int) int { return i }) // never closed, nor stopped
+	})
+	if len(failures) != 1 || !strings.Contains(failures[0], "chanz.Map") {
+		t.Logf("expected, the Map stage to leak, but got %v", failures)
+		t.Fail()
+	}
+
+	stages := Stages()
+	if len(stages) == 0 {
+		t.Log("expected, the leaked stage to be listed")
+		t.Fail()
+	}
+
+	close(in)
+	ExpectClosed(t, out, time.Second)
+}
@@ -0,0 +1,118 @@
+package chanztest
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Source is an input chan for a stage under test that is fed from the test, one element at a time, so that the
+// test controls what the stage sees and when, and can tell whether the stage applies backpressure.
+// The methods of Source may fail the test and must be called from the goroutine running it.
+//
+// Example:
+//
+//	in := chanztest.NewSource[int](t, 0)
+//	out := chanz.Map(in.C(), double)
+//	in.Send(1)              // accepted by the stage, which now blocks on out
+//	in.ExpectBlocked(2)     // nobody reads out, so the stage does not take another element
+//	chanztest.ExpectSequence(t, out, time.Second, 2)
+//	in.Close()
+type Source[T any] struct {
+	t      testing.TB
+	c      chan T
+	once   sync.Once
+	mu     sync.Mutex
+	sent   int
+	closed bool
+}
+
+// NewSource creates a Source whose chan has a buffer of buffer size.
+func NewSource[T any](t testing.TB, buffer int) *Source[T] {
+	return &Source[T]{t: t, c: make(chan T, buffer)}
+}
+
+// C returns the chan to pass to the stage under test.
+func (s *Source[T]) C() <-chan T {
+	return s.c
+}
+
+// Send puts every element of vs on the chan, in order, and fails the test if one of them is not accepted within
+// DefaultTimeout, e.g. because the stage is blocked.
+func (s *Source[T]) Send(vs ...T) {
+	s.t.Helper()
+	for i, v := range vs {
+		s.checkOpen()
+		select {
+		case s.c <- v:
+			s.count()
+		case <-time.After(DefaultTimeout):
+			s.t.Fatalf("expected %v, element %d of %v, to be accepted within %v", v, i, vs, DefaultTimeout)
+		}
+	}
+}
+
+// TrySend puts v on the chan if it is accepted right away, and returns whether it was.
+func (s *Source[T]) TrySend(v T) bool {
+	s.t.Helper()
+	s.checkOpen()
+	select {
+	case s.c <- v:
+		s.count()
+		return true
+	default:
+		return false
+	}
+}
+
+// ExpectBlocked fails the test if v is accepted within ShortWait, which tells that the stage applies backpressure.
+// If v is not accepted it is not sent.
+func (s *Source[T]) ExpectBlocked(v T) {
+	s.t.Helper()
+	s.checkOpen()
+	select {
+	case s.c <- v:
+		s.count()
+		s.t.Fatalf("expected %v to be blocked, but it was accepted", v)
+	case <-time.After(ShortWait):
+	}
+}
+
+// Sent returns the number of elements that have been accepted.
+func (s *Source[T]) Sent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent
+}
+
+// Pending returns the number of elements in the buffer of the chan, that are sent but not yet received by the stage.
+func (s *Source[T]) Pending() int {
+	return len(s.c)
+}
+
+// Close closes the chan. It is safe to call Close more than once.
+func (s *Source[T]) Close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.c)
+	})
+}
+
+func (s *Source[T]) count() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent++
+}
+
+// checkOpen fails the test, rather than panicking, when sending on a closed Source
+func (s *Source[T]) checkOpen() {
+	s.t.Helper()
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		s.t.Fatal("expected the source to be open, but it is closed")
+	}
+}
@@ -0,0 +1,75 @@
+package chanztest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+)
+
+func TestSourceBackpressure(t *testing.T) {
+	in := NewSource[int](t, 0)
+	out := chanz.Map(in.C(), func(i int) int { return i * 2 })
+
+	in.Send(1)          // taken by the stage, which then blocks on out
+	in.ExpectBlocked(2) // so nothing more is taken
+	if in.TrySend(2) {
+		t.Log("expected, TrySend to a blocked stage to fail")
+		t.Fail()
+	}
+
+	ExpectSequence(t, out, time.Second, 2)
+	in.Send(3)
+	ExpectSequence(t, out, time.Second, 6)
+	if in.Sent() != 2 {
+		t.Logf("expected, 2, but got %v", in.Sent())
+		t.Fail()
+	}
+
+	in.Close()
+	in.Close()
+	ExpectClosed(t, out, time.Second)
+}
+
+func TestSourceBuffer(t *testing.T) {
+	in := NewSource[int](t, 2)
+	in.Send(1, 2)
+	if in.Pending() != 2 {
+		t.Logf("expected, 2, but got %v", in.Pending())
+		t.Fail()
+	}
+	in.Close()
+	ExpectSequence(t, in.C(), time.Second, 1, 2)
+}
+
+func TestSourceFailures(t *testing.T) {
+	defer func(d time.Duration) { DefaultTimeout = d }(DefaultTimeout)
+	DefaultTimeout = 10 * time.Millisecond
+
+	failures := record(t, func(tb testing.TB) {
+		NewSource[int](tb, 0).Send(1) // nobody reads
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a blocked send to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		in := NewSource[int](tb, 1)
+		in.ExpectBlocked(1) // there is room in the buffer
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, an accepted element to fail, but got %v", failures)
+		t.Fail()
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		in := NewSource[int](tb, 1)
+		in.Close()
+		in.Send(1)
+	})
+	if len(failures) != 1 {
+		t.Logf("expected, a send on a closed source to fail, but got %v", failures)
+		t.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+package chanz
+
+import "time"
+
+// Clock is the source of time used by time based stages such as Batch, Throttle, Debounce and Sample.
+// The default is the wall clock, use OpClock to supply another one, e.g. a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock equivalent of a time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock equivalent of a time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// OpClock creates an option that makes time based stages use clock instead of the wall clock.
+func OpClock(clock Clock) Option {
+	return func(s settings) settings {
+		s.clock = clock
+		return s
+	}
+}
+
+// getClock returns the configured clock or the wall clock if none was supplied
+func (s settings) getClock() Clock {
+	if s.clock == nil {
+		return wallClock{}
+	}
+	return s.clock
+}
+
+// wallClock implements Clock using the time package.
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTimer(d time.Duration) Timer {
+	return wallTimer{time.NewTimer(d)}
+}
+
+func (wallClock) NewTicker(d time.Duration) Ticker {
+	return wallTicker{time.NewTicker(d)}
+}
+
+type wallTimer struct {
+	*time.Timer
+}
+
+func (t wallTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type wallTicker struct {
+	*time.Ticker
+}
+
+func (t wallTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
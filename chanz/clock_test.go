@@ -0,0 +1,52 @@
+package chanz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+)
+
+// start is the time fake clocks start at, 2022-01-01 00:00, a Saturday
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestWallClock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	select {
+	case <-chanz.After(time.Millisecond, chanz.OpContext(ctx)):
+	case <-time.After(time.Second):
+		t.Error("expected timer to fire")
+	}
+
+	ticks := chanz.Interval(time.Millisecond, chanz.OpContext(ctx))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(time.Second):
+			t.Error("expected ticker to tick")
+		}
+	}
+}
+
+func TestBatchClock(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int)
+	batches := chanz.Batch(in, 10, time.Minute, chanz.OpClock(clock))
+
+	in <- 1
+	in <- 2
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(59 * time.Second)
+	chanztest.ExpectNothing(t, batches, chanztest.ShortWait)
+
+	clock.Advance(time.Second)
+	res := <-batches
+	if len(res) != 2 {
+		t.Errorf("expected batch of 2, got %v", res)
+	}
+	close(in)
+}
@@ -0,0 +1,142 @@
+package chanz
+
+// CombineLatest takes two chans and returns a chan. Whenever either side receives an element, the combiner is applied
+// to it and the latest element of the other side and the result is put on the returning chan.
+// Nothing is emitted until both sides have received their first element.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once both "ac" and "bc" are closed, or one of them is closed before receiving any element,
+// or once the "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	prices := chanz.Generate(100, 101, 102)
+//	rates := chanz.Generate(1.0, 1.1)
+//	converted := chanz.CombineLatest(prices, rates, func(p int, r float64) float64 { return float64(p) * r })
+//	// converted receives a price each time either the price or the rate changes
+func CombineLatest[A any, B any, C any](ac <-chan A, bc <-chan B, combiner func(a A, b B) C, options ...Option) <-chan C {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan C, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, ac)
+			discard(s, bc)
+		})
+
+		var a A
+		var b B
+		var hasA, hasB bool
+		for ac != nil || bc != nil {
+			select {
+			case <-s.stop():
+				return
+			case e, ok := <-ac:
+				if !ok {
+					if !hasA {
+						return
+					}
+					ac = nil
+					continue
+				}
+				s.observe(EventReceived, 0)
+				a, hasA = e, true
+			case e, ok := <-bc:
+				if !ok {
+					if !hasB {
+						return
+					}
+					bc = nil
+					continue
+				}
+				s.observe(EventReceived, 0)
+				b, hasB = e, true
+			}
+
+			if !hasA || !hasB {
+				continue
+			}
+			c, ok := call(s, func(b B) C { return combiner(a, b) }, b)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, out, c) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// WithLatestFrom takes a primary and a secondary chan and returns a chan. Whenever primary receives an element, the
+// combiner is applied to it and the latest element of secondary and the result is put on the returning chan.
+// Elements on secondary never trigger any output, and primary elements received before secondary has received
+// its first element are dropped. The latest secondary element is kept after secondary is closed.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "primary", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	orders := incomingOrders()
+//	configs := configUpdates()
+//	validated := chanz.WithLatestFrom(orders, configs, func(o Order, c Config) Result {
+//	    return validate(o, c)
+//	})
+func WithLatestFrom[A any, B any, C any](primary <-chan A, secondary <-chan B, combiner func(a A, b B) C, options ...Option) <-chan C {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan C, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, primary)
+			discard(s, secondary)
+		})
+
+		var b B
+		var hasB bool
+		for {
+			select {
+			case <-s.stop():
+				return
+			case e, ok := <-secondary:
+				if !ok {
+					secondary = nil
+					continue
+				}
+				s.observe(EventReceived, 0)
+				b, hasB = e, true
+			case a, ok := <-primary:
+				if !ok {
+					return
+				}
+				s.observe(EventReceived, 0)
+				if !hasB {
+					continue
+				}
+				c, ok := call(s, func(a A) C { return combiner(a, b) }, a)
+				if !ok {
+					if s.recoverStop {
+						return
+					}
+					continue
+				}
+				if !send(s, out, c) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,79 @@
+package chanz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestCombineLatest(t *testing.T) {
+	ac := make(chan int)
+	bc := make(chan string)
+	combined := CombineLatest(ac, bc, func(a int, b string) string {
+		return fmt.Sprintf("%d%s", a, b)
+	})
+
+	ac <- 1
+	ac <- 2
+	expectNothing(t, combined)
+
+	bc <- "a"
+	expectNext(t, combined, "2a")
+	ac <- 3
+	expectNext(t, combined, "3a")
+	bc <- "b"
+	expectNext(t, combined, "3b")
+
+	close(ac)
+	bc <- "c"
+	expectNext(t, combined, "3c")
+	close(bc)
+	expectClosed(t, combined)
+}
+
+func TestCombineLatestEmptySide(t *testing.T) {
+	ac := make(chan int)
+	bc := make(chan int)
+	combined := CombineLatest(ac, bc, func(a int, b int) int { return a + b })
+
+	ac <- 1
+	close(bc)
+	expectClosed(t, combined)
+}
+
+func TestCombineLatestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	combined := CombineLatest(make(chan int), make(chan int), func(a int, b int) int { return a + b }, OpContext(ctx))
+	cancel()
+	expectClosed(t, combined)
+}
+
+func TestWithLatestFrom(t *testing.T) {
+	primary := make(chan int)
+	secondary := make(chan string)
+	combined := WithLatestFrom(primary, secondary, func(a int, b string) string {
+		return fmt.Sprintf("%d%s", a, b)
+	})
+
+	primary <- 1 // dropped, no secondary yet
+	secondary <- "a"
+	secondary <- "b"
+	expectNothing(t, combined)
+
+	primary <- 2
+	expectNext(t, combined, "2b")
+
+	close(secondary)
+	primary <- 3
+	expectNext(t, combined, "3b")
+
+	close(primary)
+	expectClosed(t, combined)
+}
+
+func TestWithLatestFromContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	combined := WithLatestFrom(make(chan int), make(chan int), func(a int, b int) int { return a + b }, OpContext(ctx))
+	cancel()
+	expectClosed(t, combined)
+}
@@ -0,0 +1,244 @@
+package chanz
+
+import "context"
+
+// stageContext returns the context passed to the functions of Ctx variants of stages. It is derived from the context
+// supplied with OpContext, or context.Background if none, and is cancelled once the stage is stopped
+// or the returned cancel func is called, which the stage does when it stops.
+// A draining stage is not stopped by the context supplied with OpContext, so neither is the context it passes on.
+func (s settings) stageContext() (context.Context, context.CancelFunc) {
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	if s.drain {
+		parent = detachedContext{parent}
+	}
+	ctx, cancel := context.WithCancel(parent)
+	if stop := s.stop(); stop != nil {
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
+// MapCtx is like Map, but mapper also receives a context that is cancelled once the stage is cancelled, which lets
+// cancellation reach the work being done, e.g. an in-flight HTTP request.
+// The context is derived from the context supplied with OpContext and is also cancelled once the "done" channel,
+// supplied with OpDone, is closed. The result of a mapper returning after the context is cancelled is dropped.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	bodies := chanz.MapCtx(urls, func(ctx context.Context, url string) []byte {
+//	    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	    return fetch(req) // aborted once the timeout is hit
+//	}, chanz.OpContext(ctx))
+func MapCtx[A any, B any](in <-chan A, mapper func(ctx context.Context, a A) B, options ...Option) <-chan B {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	ctx, cancel := s.stageContext()
+	apply := func(a A) B {
+		return mapper(ctx, a)
+	}
+
+	out := make(chan B, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
+		defer cancel()
+		for e := range in {
+			s.observe(EventReceived, 0)
+			b, ok := call(s, apply, e)
+			if ctx.Err() != nil {
+				return
+			}
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, out, b) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MapCtxWith returns a configured MapCtx function closure.
+// Allows creating reusable mappers with preset options.
+//
+// Example:
+//
+//	fetcher := chanz.MapCtxWith[string, []byte](chanz.OpContext(ctx), chanz.OpBuffer(10))
+//	bodies := fetcher(urls, fetchBody)
+func MapCtxWith[A any, B any](options ...Option) func(in <-chan A, mapper func(ctx context.Context, a A) B) <-chan B {
+	return func(in <-chan A, mapper func(ctx context.Context, a A) B) <-chan B {
+		return MapCtx(in, mapper, options...)
+	}
+}
+
+// FilterCtx is like Filter, but include also receives a context that is cancelled once the stage is cancelled.
+// The context is derived from the context supplied with OpContext and is also cancelled once the "done" channel,
+// supplied with OpDone, is closed.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	allowed := chanz.FilterCtx(users, func(ctx context.Context, u User) bool {
+//	    ok, err := acl.Check(ctx, u.ID)
+//	    return err == nil && ok
+//	}, chanz.OpContext(ctx))
+func FilterCtx[A any](c <-chan A, include func(ctx context.Context, a A) bool, options ...Option) <-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	ctx, cancel := s.stageContext()
+	apply := func(a A) bool {
+		return include(ctx, a)
+	}
+
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, c)
+		})
+		defer cancel()
+		for e := range c {
+			s.observe(EventReceived, 0)
+			keep, ok := call(s, apply, e)
+			if ctx.Err() != nil {
+				return
+			}
+			if !ok && s.recoverStop {
+				return
+			}
+			if !keep {
+				continue
+			}
+			if !send(s, out, e) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// FilterCtxWith returns a configured FilterCtx function closure.
+// Allows creating reusable filters with preset options.
+//
+// Example:
+//
+//	authorized := chanz.FilterCtxWith[User](chanz.OpContext(ctx))
+//	allowed := authorized(users, checkACL)
+func FilterCtxWith[A any](options ...Option) func(c <-chan A, include func(ctx context.Context, a A) bool) <-chan A {
+	return func(c <-chan A, include func(ctx context.Context, a A) bool) <-chan A {
+		return FilterCtx(c, include, options...)
+	}
+}
+
+// PeekCtx is like Peek, but apply also receives a context that is cancelled once the stage is cancelled.
+// The context is derived from the context supplied with OpContext and is also cancelled once the "done" channel,
+// supplied with OpDone, is closed.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	audited := chanz.PeekCtx(orders, func(ctx context.Context, o Order) {
+//	    _ = auditLog.Write(ctx, o)
+//	}, chanz.OpContext(ctx))
+func PeekCtx[A any](in <-chan A, apply func(ctx context.Context, a A), options ...Option) <-chan A {
+	return MapCtx(in, func(ctx context.Context, a A) A {
+		apply(ctx, a)
+		return a
+	}, options...)
+}
+
+// PeekCtxWith returns a configured PeekCtx function closure.
+// Allows creating reusable peekers with preset options.
+//
+// Example:
+//
+//	auditor := chanz.PeekCtxWith[Order](chanz.OpContext(ctx))
+//	audited := auditor(orders, writeAuditLog)
+func PeekCtxWith[A any](options ...Option) func(in <-chan A, apply func(ctx context.Context, a A)) <-chan A {
+	return func(in <-chan A, apply func(ctx context.Context, a A)) <-chan A {
+		return PeekCtx(in, apply, options...)
+	}
+}
+
+// GeneratorCtx is like Generator, but gen also receives a context that is cancelled once the stage is cancelled,
+// which gen should use to stop generating values and to abort any blocking work.
+// The context is derived from the context supplied with OpContext and is also cancelled once the "done" channel,
+// supplied with OpDone, is closed.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+//
+// Example:
+//
+//	rows := chanz.GeneratorCtx(func(ctx context.Context, yield func(Row)) {
+//	    res, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+//	    if err != nil {
+//	        return
+//	    }
+//	    defer res.Close()
+//	    for res.Next() {
+//	        var r Row
+//	        _ = res.Scan(&r.ID, &r.Name)
+//	        yield(r)
+//	    }
+//	}, chanz.OpContext(ctx))
+func GeneratorCtx[A any](gen func(ctx context.Context, yield func(A)), options ...Option) <-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	ctx, cancel := s.stageContext()
+	out := make(chan A, s.buffer)
+	yield := func(a A) {
+		send(s, out, a)
+	}
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+		defer cancel()
+		try(s, func() { gen(ctx, yield) })
+	}()
+	return out
+}
+
+// GeneratorCtxWith returns a configured GeneratorCtx function closure.
+// Allows creating reusable generators with preset options.
+//
+// Example:
+//
+//	queryer := chanz.GeneratorCtxWith[Row](chanz.OpContext(ctx), chanz.OpBuffer(100))
+//	rows := queryer(queryUsers)
+func GeneratorCtxWith[A any](options ...Option) func(gen func(ctx context.Context, yield func(A))) <-chan A {
+	return func(gen func(ctx context.Context, yield func(A))) <-chan A {
+		return GeneratorCtx(gen, options...)
+	}
+}
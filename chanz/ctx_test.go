@@ -0,0 +1,154 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestMapCtx(t *testing.T) {
+	res := Collect(MapCtx(Generate(1, 2, 3), func(ctx context.Context, i int) int {
+		if ctx.Err() != nil {
+			t.Logf("expected, a live context, but got %v", ctx.Err())
+			t.Fail()
+		}
+		return i * 2
+	}))
+
+	exp := []int{2, 4, 6}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestMapCtxCancelInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aborted := make(chan error, 1)
+	mapped := MapCtx(Generate(1, 2, 3), func(ctx context.Context, i int) int {
+		if i == 2 {
+			<-ctx.Done() // simulates a slow request that only returns once aborted
+			aborted <- ctx.Err()
+		}
+		return i
+	}, OpContext(ctx))
+
+	expectNext(t, mapped, 1)
+	cancel()
+
+	select {
+	case err := <-aborted:
+		if err != context.Canceled {
+			t.Logf("expected, %v, but got %v", context.Canceled, err)
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected, the in flight mapper to be cancelled")
+	}
+	expectClosed(t, mapped)
+}
+
+func TestMapCtxDone(t *testing.T) {
+	done := make(chan struct{})
+	aborted := make(chan struct{})
+	mapped := MapCtx(Generate(1, 2), func(ctx context.Context, i int) int {
+		<-ctx.Done()
+		close(aborted)
+		return i
+	}, OpDone(done))
+
+	close(done)
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("expected, closing done to cancel the mapper context")
+	}
+	expectClosed(t, mapped)
+}
+
+func TestMapCtxCancelledOnExit(t *testing.T) {
+	var stageCtx context.Context
+	Collect(MapCtx(Generate(1), func(ctx context.Context, i int) int {
+		stageCtx = ctx
+		return i
+	}))
+	if stageCtx.Err() == nil {
+		t.Logf("expected, context to be cancelled once the stage has stopped")
+		t.Fail()
+	}
+}
+
+func TestFilterCtx(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, 2)
+
+	res := Collect(FilterCtx(Generate(1, 2, 3, 4, 5, 6), func(ctx context.Context, i int) bool {
+		return i%ctx.Value(key{}).(int) == 0
+	}, OpContext(ctx)))
+
+	exp := []int{2, 4, 6}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestPeekCtx(t *testing.T) {
+	var seen []int
+	res := Collect(PeekCtx(Generate(1, 2, 3), func(ctx context.Context, i int) {
+		seen = append(seen, i)
+	}))
+
+	exp := []int{1, 2, 3}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if !slicez.Equal(seen, exp) {
+		t.Logf("expected, %v, but got %v", exp, seen)
+		t.Fail()
+	}
+}
+
+func TestGeneratorCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan struct{})
+	gen := GeneratorCtx(func(ctx context.Context, yield func(int)) {
+		defer close(stopped)
+		for i := 0; ctx.Err() == nil; i++ {
+			yield(i)
+		}
+	}, OpContext(ctx))
+
+	expectNext(t, gen, 0)
+	expectNext(t, gen, 1)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected, generator to observe cancellation")
+	}
+	DropAll(gen, false)
+}
+
+func TestGeneratorCtxWith(t *testing.T) {
+	counter := GeneratorCtxWith[int](OpBuffer(3))
+	res := Collect(counter(func(ctx context.Context, yield func(int)) {
+		for i := 1; i <= 3 && ctx.Err() == nil; i++ {
+			yield(i)
+		}
+	}))
+
+	exp := []int{1, 2, 3}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
@@ -0,0 +1,145 @@
+package chanz
+
+import (
+	"hash/fnv"
+	"reflect"
+)
+
+// Strategy decides which of the outputs of Distribute an element is handed to.
+// It must send a to exactly one of outs, or give up and return false once done is closed.
+// Strategies may keep state between calls, but are only ever called from one goroutine.
+type Strategy[A any] func(outs []chan A, a A, done <-chan struct{}) bool
+
+// RoundRobin returns a Strategy that hands elements to the outputs in turn.
+// An element is not sent to the next output until the current one has accepted it.
+func RoundRobin[A any]() Strategy[A] {
+	var next int
+	return func(outs []chan A, a A, done <-chan struct{}) bool {
+		out := outs[next]
+		next = (next + 1) % len(outs)
+		select {
+		case <-done:
+			return false
+		case out <- a:
+			return true
+		}
+	}
+}
+
+// FirstFree returns a Strategy that hands elements to whichever output is able to accept it first.
+// If several outputs have room, the one with the fewest buffered elements is picked, making it a
+// least-busy strategy. If no output is ready, it blocks until any of them is.
+func FirstFree[A any]() Strategy[A] {
+	return func(outs []chan A, a A, done <-chan struct{}) bool {
+		least := -1
+		for i, out := range outs {
+			if len(out) < cap(out) && (least == -1 || len(out) < len(outs[least])) {
+				least = i
+			}
+		}
+		if least != -1 {
+			select {
+			case outs[least] <- a:
+				return true
+			default:
+			}
+		}
+
+		cases := make([]reflect.SelectCase, 0, len(outs)+1)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+		val := reflect.ValueOf(a)
+		if !val.IsValid() { // a is a nil interface
+			val = reflect.Zero(reflect.TypeOf(outs).Elem().Elem())
+		}
+		for _, out := range outs {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(out), Send: val})
+		}
+		chosen, _, _ := reflect.Select(cases)
+		return chosen != 0
+	}
+}
+
+// KeyHash returns a Strategy that hands elements to an output picked by hashing the key of the element.
+// All elements with the same key end up on the same output, which preserves per key ordering.
+func KeyHash[A any](key func(a A) string) Strategy[A] {
+	return func(outs []chan A, a A, done <-chan struct{}) bool {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key(a)))
+		out := outs[h.Sum32()%uint32(len(outs))]
+		select {
+		case <-done:
+			return false
+		case out <- a:
+			return true
+		}
+	}
+}
+
+// Distribute splits one input channel into multiple output channels.
+// Unlike FanOut, each value from the input is sent to exactly one of the outputs (work distribution pattern),
+// and the strategy decides which one. Use RoundRobin, FirstFree or KeyHash, or write your own Strategy.
+// Output channels are closed when input closes.
+// The observer supplied with OpObserver is told about every element handed to an output, but not about time spent
+// waiting for one, which happens within the strategy.
+// The return chans has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3, 4, 5, 6)
+//	outputs := chanz.Distribute(input, 3, chanz.RoundRobin[int](), chanz.OpBuffer(2))
+//	// outputs[0] receives 1, 4; outputs[1] receives 2, 5; outputs[2] receives 3, 6
+func Distribute[A any](c <-chan A, size int, strategy Strategy[A], options ...Option) []<-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	outs := make([]chan A, size)
+	for i := range outs {
+		outs[i] = make(chan A, s.buffer)
+	}
+
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			for _, o := range outs {
+				close(o)
+			}
+			discard(s, c)
+		})
+		if size < 1 {
+			return
+		}
+
+		for e := range c {
+			s.observe(EventReceived, 0)
+			var sent bool
+			if !try(s, func() { sent = strategy(outs, e, s.stop()) }) {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !sent {
+				return
+			}
+			s.observe(EventEmitted, 0)
+		}
+	}()
+	return Readers(outs...)
+}
+
+// DistributeWith returns a configured Distribute function closure.
+// Allows creating reusable distributors with preset options.
+//
+// Example:
+//
+//	distributor := chanz.DistributeWith[int](OpBuffer(5))
+//	input := chanz.Generate(1, 2, 3)
+//	outputs := distributor(input, 3, chanz.FirstFree[int]())
+func DistributeWith[A any](options ...Option) func(c <-chan A, size int, strategy Strategy[A]) []<-chan A {
+	return func(c <-chan A, size int, strategy Strategy[A]) []<-chan A {
+		return Distribute(c, size, strategy, options...)
+	}
+}
@@ -0,0 +1,136 @@
+package chanz
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func collectAll[A any](outs []<-chan A) [][]A {
+	res := make([][]A, len(outs))
+	var wg sync.WaitGroup
+	wg.Add(len(outs))
+	for i, o := range outs {
+		i, o := i, o
+		go func() {
+			defer wg.Done()
+			res[i] = Collect(o)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+func TestDistributeRoundRobin(t *testing.T) {
+	outs := Distribute(Generate(1, 2, 3, 4, 5, 6, 7), 3, RoundRobin[int]())
+	res := collectAll(outs)
+
+	exp := [][]int{{1, 4, 7}, {2, 5}, {3, 6}}
+	for i := range exp {
+		if !slicez.Equal(exp[i], res[i]) {
+			t.Logf("expected, %v, but got %v, for output %d", exp[i], res[i], i)
+			t.Fail()
+		}
+	}
+}
+
+func TestDistributeFirstFree(t *testing.T) {
+	outs := Distribute(Generate(1, 2, 3, 4, 5, 6, 7, 8, 9), 3, FirstFree[int]())
+
+	// Only output 1 is consumed, so every element must end up there
+	res := Collect(outs[1])
+	exp := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	for _, i := range []int{0, 2} {
+		if r := Collect(outs[i]); len(r) != 0 {
+			t.Errorf("expected output %d to be empty, got %v", i, r)
+		}
+	}
+}
+
+func TestDistributeFirstFreeLeastBusy(t *testing.T) {
+	outs := Distribute(Generate(1, 2, 3, 4), 2, FirstFree[int](), OpBuffer(2))
+	time.Sleep(50 * time.Millisecond)
+
+	if len(outs[0]) != 2 || len(outs[1]) != 2 {
+		t.Errorf("expected elements to be spread evenly, got %d and %d", len(outs[0]), len(outs[1]))
+	}
+	res := slicez.Sort(slicez.Flatten(collectAll(outs)))
+	exp := []int{1, 2, 3, 4}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestDistributeKeyHash(t *testing.T) {
+	in := Generate(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+	outs := Distribute(in, 4, KeyHash(func(a int) string {
+		return strconv.Itoa(a % 3)
+	}))
+	res := collectAll(outs)
+
+	var total int
+	for _, r := range res {
+		total += len(r)
+		if len(r) == 0 {
+			continue
+		}
+		for _, e := range r {
+			if e%3 != r[0]%3 {
+				t.Errorf("expected all elements of an output to share key, got %v", r)
+				break
+			}
+		}
+		if !slicez.IsSorted(r) {
+			t.Errorf("expected per key order to be preserved, got %v", r)
+		}
+	}
+	if total != 12 {
+		t.Errorf("expected 12 elements, got %d", total)
+	}
+}
+
+func TestDistributeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := Generator(func(yield func(int)) {
+		for i := 0; ctx.Err() == nil; i++ {
+			yield(i)
+		}
+	}, OpContext(ctx))
+	outs := Distribute(in, 2, RoundRobin[int](), OpContext(ctx))
+	<-outs[0]
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		collectAll(outs)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("expected outputs to close after cancel")
+	}
+}
+
+func TestDistributeWith(t *testing.T) {
+	distributor := DistributeWith[int](OpBuffer(3))
+	outs := distributor(Generate(1, 2, 3), 3, RoundRobin[int]())
+	res := collectAll(outs)
+
+	exp := [][]int{{1}, {2}, {3}}
+	for i := range exp {
+		if !slicez.Equal(exp[i], res[i]) {
+			t.Logf("expected, %v, but got %v, for output %d", exp[i], res[i], i)
+			t.Fail()
+		}
+	}
+}
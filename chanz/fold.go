@@ -0,0 +1,124 @@
+package chanz
+
+import (
+	"github.com/modfin/henry/mapz"
+)
+
+// Fold reduces all elements in a channel to a single value, starting with init, and returns it once c is closed.
+// The stream is aggregated as it arrives, so unlike slicez.Fold(chanz.Collect(c), ...) it never holds all elements in memory.
+// It stops and returns the accumulated value so far once "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3, 4)
+//	sum := chanz.Fold(input, func(acc, n int) int { return acc + n }, 0)
+//	// sum = 10
+func Fold[I any, A any](c <-chan I, combined func(accumulator A, val I) A, init A, options ...Option) A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	for val := range c {
+		acc, ok := call(s, func(val I) A { return combined(init, val) }, val)
+		if !ok && s.recoverStop {
+			go discard(s, c)
+			return init
+		}
+		if ok {
+			init = acc
+		}
+		select {
+		case <-s.stop():
+			return init
+		default:
+		}
+	}
+	return init
+}
+
+// Count consumes a channel and returns the number of elements received before it was closed.
+// It stops and returns the count so far once "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3, 4)
+//	n := chanz.Count(input)
+//	// n = 4
+func Count[A any](c <-chan A, options ...Option) int {
+	return Fold(c, func(acc int, _ A) int {
+		return acc + 1
+	}, 0, options...)
+}
+
+// Scan takes a chan and returns a chan of the running accumulator. For every element received the accumulator,
+// starting with init, is combined with the element and the result is put on the returning chan.
+// Unlike slicez.ScanLeft, init itself is not emitted.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3, 4)
+//	sums := chanz.Scan(input, func(acc, n int) int { return acc + n }, 0)
+//	result := chanz.Collect(sums)
+//	// result = []int{1, 3, 6, 10}
+func Scan[I any, A any](in <-chan I, combine func(accumulator A, val I) A, init A, options ...Option) <-chan A {
+	return Map(in, func(val I) A {
+		init = combine(init, val)
+		return init
+	}, options...)
+}
+
+// ScanWith returns a configured Scan function closure.
+// Allows creating reusable scanners with preset options.
+//
+// Example:
+//
+//	runningSum := chanz.ScanWith[int, int](chanz.OpBuffer(10))
+//	sums := runningSum(input, func(acc, n int) int { return acc + n }, 0)
+func ScanWith[I any, A any](options ...Option) func(in <-chan I, combine func(accumulator A, val I) A, init A) <-chan A {
+	return func(in <-chan I, combine func(accumulator A, val I) A, init A) <-chan A {
+		return Scan(in, combine, init, options...)
+	}
+}
+
+// FoldByKey takes a chan and returns a chan of per key running accumulators. Every element is grouped by the key
+// func, combined with the accumulator of its key, starting with init, and the updated entry is put on the returning chan.
+// The state held is one accumulator per distinct key, making it suitable for real time aggregation of unbounded streams.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	type Trade struct{ Symbol string; Volume int }
+//	trades := chanz.Generate(Trade{"AAPL", 10}, Trade{"MSFT", 5}, Trade{"AAPL", 20})
+//	volumes := chanz.FoldByKey(trades, func(t Trade) string { return t.Symbol },
+//	    func(acc int, t Trade) int { return acc + t.Volume }, 0)
+//	result := chanz.Collect(volumes)
+//	// result = []mapz.Entry[string, int]{{Key: "AAPL", Value: 10}, {Key: "MSFT", Value: 5}, {Key: "AAPL", Value: 30}}
+func FoldByKey[I any, K comparable, A any](in <-chan I, key func(val I) K, combine func(accumulator A, val I) A, init A, options ...Option) <-chan mapz.Entry[K, A] {
+	state := map[K]A{}
+	return Map(in, func(val I) mapz.Entry[K, A] {
+		k := key(val)
+		acc, ok := state[k]
+		if !ok {
+			acc = init
+		}
+		acc = combine(acc, val)
+		state[k] = acc
+		return mapz.Entry[K, A]{Key: k, Value: acc}
+	}, options...)
+}
+
+// FoldByKeyWith returns a configured FoldByKey function closure.
+// Allows creating reusable keyed aggregations with preset options.
+//
+// Example:
+//
+//	aggregator := chanz.FoldByKeyWith[Trade, string, int](chanz.OpContext(ctx))
+//	volumes := aggregator(trades, bySymbol, addVolume, 0)
+func FoldByKeyWith[I any, K comparable, A any](options ...Option) func(in <-chan I, key func(val I) K, combine func(accumulator A, val I) A, init A) <-chan mapz.Entry[K, A] {
+	return func(in <-chan I, key func(val I) K, combine func(accumulator A, val I) A, init A) <-chan mapz.Entry[K, A] {
+		return FoldByKey(in, key, combine, init, options...)
+	}
+}
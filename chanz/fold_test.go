@@ -0,0 +1,103 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modfin/henry/mapz"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestFold(t *testing.T) {
+	res := Fold(Generate("a", "b", "c"), func(acc string, val string) string {
+		return acc + val
+	}, ">")
+	if res != ">abc" {
+		t.Errorf("expected >abc, got %v", res)
+	}
+}
+
+func TestFoldDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := Generator(func(yield func(int)) {
+		for ctx.Err() == nil {
+			yield(1)
+		}
+	}, OpContext(ctx))
+
+	done := make(chan struct{})
+	close(done)
+	res := Fold(in, func(acc int, val int) int {
+		return acc + val
+	}, 0, OpDone(done))
+	if res < 1 {
+		t.Errorf("expected fold to have consumed some elements, got %v", res)
+	}
+}
+
+func TestCount(t *testing.T) {
+	if n := Count(Generate(1, 2, 3, 4)); n != 4 {
+		t.Errorf("expected 4, got %d", n)
+	}
+	if n := Count(Generate[int]()); n != 0 {
+		t.Errorf("expected 0, got %d", n)
+	}
+}
+
+func TestScan(t *testing.T) {
+	res := Collect(Scan(Generate(1, 2, 3, 4), func(acc int, val int) int {
+		return acc + val
+	}, 0))
+	exp := []int{1, 3, 6, 10}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestScanWith(t *testing.T) {
+	scanner := ScanWith[int, []int](OpBuffer(1))
+	res := Collect(scanner(Generate(1, 2), func(acc []int, val int) []int {
+		return append(slicez.Clone(acc), val)
+	}, nil))
+	exp := [][]int{{1}, {1, 2}}
+	if !slicez.EqualBy(exp, res, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestFoldByKey(t *testing.T) {
+	type trade struct {
+		symbol string
+		volume int
+	}
+	trades := Generate(trade{"AAPL", 10}, trade{"MSFT", 5}, trade{"AAPL", 20}, trade{"MSFT", 1})
+	res := Collect(FoldByKey(trades, func(t trade) string {
+		return t.symbol
+	}, func(acc int, t trade) int {
+		return acc + t.volume
+	}, 100))
+
+	exp := []mapz.Entry[string, int]{{Key: "AAPL", Value: 110}, {Key: "MSFT", Value: 105}, {Key: "AAPL", Value: 130}, {Key: "MSFT", Value: 106}}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestFoldByKeyWith(t *testing.T) {
+	counter := FoldByKeyWith[int, bool, int](OpBuffer(2))
+	res := Collect(counter(Generate(1, 2, 3), func(val int) bool {
+		return val%2 == 0
+	}, func(acc int, _ int) int {
+		return acc + 1
+	}, 0))
+
+	exp := []mapz.Entry[bool, int]{{Key: false, Value: 1}, {Key: true, Value: 1}, {Key: false, Value: 2}}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
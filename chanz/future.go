@@ -0,0 +1,186 @@
+package chanz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modfin/henry/mon"
+)
+
+// ErrNoFutures is the error AnyOf and Race resolve with when given no futures.
+var ErrNoFutures = errors.New("chanz: no futures")
+
+// Future is the result of a func running in its own goroutine, see Go.
+// The result is stored in the Future, so the goroutine never blocks, and never leaks, waiting for someone to
+// receive it, no matter if the Future is awaited or not.
+type Future[T any] struct {
+	done   chan struct{}
+	result mon.Result[T]
+}
+
+// Go runs fn in a new goroutine and returns a Future of its result.
+// A panic in fn is recovered and the Future resolves with Err(PanicError).
+//
+// Example:
+//
+//	user := chanz.Go(func() (User, error) { return client.GetUser(id) })
+//	orders := chanz.Go(func() ([]Order, error) { return client.GetOrders(id) })
+//	u, err := user.Await(ctx).Get()
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	var s settings
+	s.started()
+	go func() {
+		defer s.finished(func() { close(f.done) })
+		defer func() {
+			if p := recover(); p != nil {
+				f.result = mon.Err[T](PanicError{Value: p})
+			}
+		}()
+		f.result = mon.TupleToResult(fn())
+	}()
+	return f
+}
+
+// Resolved returns a Future that is already resolved with r.
+func Resolved[T any](r mon.Result[T]) *Future[T] {
+	f := &Future[T]{done: make(chan struct{}), result: r}
+	close(f.done)
+	return f
+}
+
+// Done returns a chan that is closed once the Future is resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits for the Future to resolve and returns its result, or Err(ctx.Err()) if ctx is done first.
+// Giving up on a Future does not stop the func it runs, but it does not leak either, the result is just dropped.
+func (f *Future[T]) Await(ctx context.Context) mon.Result[T] {
+	select {
+	case <-f.done:
+		return f.result
+	default:
+	}
+	select {
+	case <-f.done:
+		return f.result
+	case <-ctx.Done():
+		return mon.Err[T](ctx.Err())
+	}
+}
+
+// ToChan returns a chan on which the result of the Future is put once it is resolved, after which the chan is closed.
+// The return chan has a buffer of 1, so the result is never blocked on, regardless of the buffer size supplied in
+// Option.
+// It will stop, without putting the result on the chan, once the "done" channel is closed or the context.Done is
+// closed, which is supplied in Option
+//
+// Example:
+//
+//	results := chanz.FanIn(fetchA.ToChan(), fetchB.ToChan())
+//	values, err := chanz.CollectResults(results)
+func (f *Future[T]) ToChan(options ...Option) <-chan mon.Result[T] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan mon.Result[T], 1)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+		select {
+		case <-s.done:
+		case <-f.done:
+			out <- f.result
+		}
+	}()
+	return out
+}
+
+// settled waits on all futures and puts the index of every future on the returned chan, as they are resolved.
+// The chan is buffered, so waiting goroutines never block.
+func settled[T any](fs []*Future[T]) <-chan int {
+	order := make(chan int, len(fs))
+	for i, f := range fs {
+		i, f := i, f
+		go func() {
+			<-f.done
+			order <- i
+		}()
+	}
+	return order
+}
+
+// AllOf returns a Future that resolves with the values of all futures, in the order of fs, once all have succeeded,
+// or with the first error as soon as any of them fails.
+//
+// Example:
+//
+//	prices := chanz.AllOf(
+//	    chanz.Go(func() (float64, error) { return quote("AAPL") }),
+//	    chanz.Go(func() (float64, error) { return quote("MSFT") }),
+//	)
+//	values, err := prices.Await(ctx).Get()
+func AllOf[T any](fs ...*Future[T]) *Future[[]T] {
+	return Go(func() ([]T, error) {
+		values := make([]T, len(fs))
+		order := settled(fs)
+		for range fs {
+			i := <-order
+			v, err := fs[i].result.Get()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	})
+}
+
+// AnyOf returns a Future that resolves with the value of the first future to succeed. If all of them fail, it resolves
+// with an error wrapping the error of the last one to fail.
+//
+// Example:
+//
+//	replica := chanz.AnyOf(
+//	    chanz.Go(func() (Row, error) { return db1.Get(key) }),
+//	    chanz.Go(func() (Row, error) { return db2.Get(key) }),
+//	)
+func AnyOf[T any](fs ...*Future[T]) *Future[T] {
+	if len(fs) == 0 {
+		return Resolved(mon.Err[T](ErrNoFutures))
+	}
+	return Go(func() (T, error) {
+		order := settled(fs)
+		var err error
+		for range fs {
+			r := fs[<-order].result
+			if r.Ok() {
+				return r.Get()
+			}
+			err = r.Error()
+		}
+		var zero T
+		return zero, fmt.Errorf("chanz: all %d futures failed, last error: %w", len(fs), err)
+	})
+}
+
+// Race returns a Future that resolves with the result of the first future to resolve, successful or not.
+//
+// Example:
+//
+//	fastest := chanz.Race(
+//	    chanz.Go(func() (Quote, error) { return primary.Quote(sym) }),
+//	    chanz.Go(func() (Quote, error) { return backup.Quote(sym) }),
+//	)
+func Race[T any](fs ...*Future[T]) *Future[T] {
+	if len(fs) == 0 {
+		return Resolved(mon.Err[T](ErrNoFutures))
+	}
+	return Go(func() (T, error) {
+		return fs[<-settled(fs)].result.Get()
+	})
+}
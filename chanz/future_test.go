@@ -0,0 +1,149 @@
+package chanz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+// after returns a func that returns v, or err if not nil, once release is closed
+func after[T any](release <-chan struct{}, v T, err error) func() (T, error) {
+	return func() (T, error) {
+		<-release
+		return v, err
+	}
+}
+
+func TestGoAwait(t *testing.T) {
+	f := Go(func() (int, error) { return 42, nil })
+	v, err := f.Await(context.Background()).Get()
+	if err != nil || v != 42 {
+		t.Logf("expected, 42, but got %v, %v", v, err)
+		t.Fail()
+	}
+
+	errBoom := errors.New("boom")
+	f = Go(func() (int, error) { return 0, errBoom })
+	if r := f.Await(context.Background()); r.Error() != errBoom {
+		t.Logf("expected, %v, but got %v", errBoom, r.Error())
+		t.Fail()
+	}
+}
+
+func TestGoPanic(t *testing.T) {
+	f := Go(func() (int, error) { panic("boom") })
+	var pe PanicError
+	if err := f.Await(context.Background()).Error(); !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Logf("expected, PanicError{boom}, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestAwaitContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	f := Go(after(release, 1, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if r := f.Await(ctx); r.Error() != context.DeadlineExceeded {
+		t.Logf("expected, %v, but got %v", context.DeadlineExceeded, r.Error())
+		t.Fail()
+	}
+}
+
+func TestFutureToChan(t *testing.T) {
+	values, err := CollectResults(FanIn(Resolved(mon.Ok(1)).ToChan(), Go(func() (int, error) { return 2, nil }).ToChan()))
+	if err != nil || !slicez.Equal(slicez.Sort(values), []int{1, 2}) {
+		t.Logf("expected, [1 2], but got %v, %v", values, err)
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	c := Go(after(release, 1, nil)).ToChan(OpContext(ctx))
+	cancel()
+	expectClosed(t, c)
+}
+
+func TestAllOf(t *testing.T) {
+	release := make(chan struct{})
+	f := AllOf(Go(after(release, 1, nil)), Resolved(mon.Ok(2)), Go(after(release, 3, nil)))
+	expectNothing(t, f.Done())
+	close(release)
+
+	values, err := f.Await(context.Background()).Get()
+	if err != nil || !slicez.Equal(values, []int{1, 2, 3}) {
+		t.Logf("expected, [1 2 3], but got %v, %v", values, err)
+		t.Fail()
+	}
+
+	values, err = AllOf[int]().Await(context.Background()).Get()
+	if err != nil || len(values) != 0 {
+		t.Logf("expected, [], but got %v, %v", values, err)
+		t.Fail()
+	}
+}
+
+func TestAllOfFailFast(t *testing.T) {
+	errBoom := errors.New("boom")
+	never := make(chan struct{})
+	defer close(never)
+	f := AllOf(Go(after(never, 1, nil)), Resolved(mon.Err[int](errBoom)))
+
+	if r := f.Await(context.Background()); r.Error() != errBoom {
+		t.Logf("expected, %v, but got %v", errBoom, r.Error())
+		t.Fail()
+	}
+}
+
+func TestAnyOf(t *testing.T) {
+	errBoom := errors.New("boom")
+	never := make(chan struct{})
+	defer close(never)
+
+	f := AnyOf(Resolved(mon.Err[int](errBoom)), Go(after(never, 1, nil)), Resolved(mon.Ok(2)))
+	if v, err := f.Await(context.Background()).Get(); err != nil || v != 2 {
+		t.Logf("expected, 2, but got %v, %v", v, err)
+		t.Fail()
+	}
+
+	f = AnyOf(Resolved(mon.Err[int](errBoom)), Resolved(mon.Err[int](errBoom)))
+	if err := f.Await(context.Background()).Error(); !errors.Is(err, errBoom) {
+		t.Logf("expected, wrapped %v, but got %v", errBoom, err)
+		t.Fail()
+	}
+
+	if err := AnyOf[int]().Await(context.Background()).Error(); err != ErrNoFutures {
+		t.Logf("expected, %v, but got %v", ErrNoFutures, err)
+		t.Fail()
+	}
+}
+
+func TestRace(t *testing.T) {
+	errBoom := errors.New("boom")
+	never := make(chan struct{})
+	defer close(never)
+
+	f := Race(Go(after(never, 1, nil)), Resolved(mon.Err[int](errBoom)))
+	if err := f.Await(context.Background()).Error(); err != errBoom {
+		t.Logf("expected, %v, but got %v", errBoom, err)
+		t.Fail()
+	}
+
+	f = Race(Go(after(never, 1, nil)), Resolved(mon.Ok(2)))
+	if v, _ := f.Await(context.Background()).Get(); v != 2 {
+		t.Logf("expected, 2, but got %v", v)
+		t.Fail()
+	}
+
+	if err := Race[int]().Await(context.Background()).Error(); err != ErrNoFutures {
+		t.Logf("expected, %v, but got %v", ErrNoFutures, err)
+		t.Fail()
+	}
+}
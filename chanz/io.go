@@ -0,0 +1,265 @@
+package chanz
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Lines reads r line by line and puts every line, without the line ending, on the returned chan. It is ScanReader
+// with bufio.ScanLines, so a line may not be longer than bufio.MaxScanTokenSize.
+// If reading fails the error is put on the error chan, which has a buffer of 1 and is closed after the lines chan,
+// so it can be read once all lines have been received. Reaching EOF is not an error.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once r is exhausted, "done" channel is closed or the context.Done is closed, which is supplied in
+// Option. A read that is in progress is not interrupted.
+//
+// Example:
+//
+//	f, _ := os.Open("access.log")
+//	defer f.Close()
+//	lines, errs := chanz.Lines(f)
+//	failed := chanz.Filter(lines, func(l string) bool { return strings.Contains(l, " 500 ") })
+//	for l := range failed {
+//	    fmt.Println(l)
+//	}
+//	if err := <-errs; err != nil {
+//	    log.Fatal(err)
+//	}
+func Lines(r io.Reader, options ...Option) (<-chan string, <-chan error) {
+	return ScanReader(r, bufio.ScanLines, options...)
+}
+
+// LinesWith returns a configured Lines function closure.
+// Allows creating reusable line readers with preset options.
+//
+// Example:
+//
+//	lines := chanz.LinesWith(chanz.OpContext(ctx), chanz.OpBuffer(100))
+//	rows, errs := lines(conn)
+func LinesWith(options ...Option) func(r io.Reader) (<-chan string, <-chan error) {
+	return func(r io.Reader) (<-chan string, <-chan error) {
+		return Lines(r, options...)
+	}
+}
+
+// ScanReader reads r with a bufio.Scanner using split and puts every token on the returned chan.
+// If reading fails the error is put on the error chan, which has a buffer of 1 and is closed after the tokens chan,
+// so it can be read once all tokens have been received. Reaching EOF is not an error.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once r is exhausted, "done" channel is closed or the context.Done is closed, which is supplied in
+// Option. A read that is in progress is not interrupted.
+//
+// Example:
+//
+//	words, errs := chanz.ScanReader(strings.NewReader("the quick brown fox"), bufio.ScanWords)
+//	result := chanz.Collect(words)
+//	// result = []string{"the", "quick", "brown", "fox"}
+func ScanReader(r io.Reader, split bufio.SplitFunc, options ...Option) (<-chan string, <-chan error) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	out := make(chan string, s.buffer)
+	errs := make(chan error, 1)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			close(errs)
+		})
+
+		scanner := bufio.NewScanner(r)
+		scanner.Split(split)
+		for scanner.Scan() {
+			s.observe(EventReceived, 0)
+			if !send(s, out, scanner.Text()) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			errs <- err
+		}
+	}()
+	return out, errs
+}
+
+// ScanReaderWith returns a configured ScanReader function closure.
+// Allows creating reusable token readers with preset options.
+//
+// Example:
+//
+//	words := chanz.ScanReaderWith(chanz.OpBuffer(100))
+//	tokens, errs := words(f, bufio.ScanWords)
+func ScanReaderWith(options ...Option) func(r io.Reader, split bufio.SplitFunc) (<-chan string, <-chan error) {
+	return func(r io.Reader, split bufio.SplitFunc) (<-chan string, <-chan error) {
+		return ScanReader(r, split, options...)
+	}
+}
+
+// WriteLines writes every string received from in to w, each followed by a newline, until in is closed.
+// Writes are buffered, and flushed whenever no further string is waiting on in, so slow streams are not held back.
+// It returns the first error from writing, after which in is no longer read, use DropAll to drain it if needed.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	upper := chanz.Map(lines, strings.ToUpper)
+//	if err := chanz.WriteLines(os.Stdout, upper); err != nil {
+//	    log.Fatal(err)
+//	}
+func WriteLines(w io.Writer, in <-chan string, options ...Option) error {
+	return writeTo(w, in, func(bw *bufio.Writer, line string) error {
+		_, err := bw.WriteString(line)
+		if err == nil {
+			err = bw.WriteByte('\n')
+		}
+		return err
+	}, options...)
+}
+
+// DecodeJSONL reads r as JSON lines, https://jsonlines.org, and puts every line decoded into a T on the returned chan.
+// Empty lines are skipped and there is no limit on the length of a line.
+// If reading or decoding fails the error, including the line number for decoding errors, is put on the error chan
+// and no more lines are read. The error chan has a buffer of 1 and is closed after the values chan, so it can be
+// read once all values have been received. Reaching EOF is not an error.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once r is exhausted, "done" channel is closed or the context.Done is closed, which is supplied in
+// Option. A read that is in progress is not interrupted.
+//
+// Example:
+//
+//	events, errs := chanz.DecodeJSONL[Event](f)
+//	clicks := chanz.Filter(events, func(e Event) bool { return e.Type == "click" })
+//	n := chanz.Count(clicks)
+//	if err := <-errs; err != nil {
+//	    log.Fatal(err)
+//	}
+func DecodeJSONL[T any](r io.Reader, options ...Option) (<-chan T, <-chan error) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	out := make(chan T, s.buffer)
+	errs := make(chan error, 1)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			close(errs)
+		})
+
+		br := bufio.NewReader(r)
+		for line := 1; ; line++ {
+			b, err := br.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				errs <- err
+				return
+			}
+			if b = bytes.TrimSpace(b); len(b) > 0 {
+				s.observe(EventReceived, 0)
+				var t T
+				if jerr := json.Unmarshal(b, &t); jerr != nil {
+					errs <- fmt.Errorf("chanz: decoding json line %d: %w", line, jerr)
+					return
+				}
+				if !send(s, out, t) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+		}
+	}()
+	return out, errs
+}
+
+// DecodeJSONLWith returns a configured DecodeJSONL function closure.
+// Allows creating reusable decoders with preset options.
+//
+// Example:
+//
+//	decode := chanz.DecodeJSONLWith[Event](chanz.OpContext(ctx), chanz.OpBuffer(100))
+//	events, errs := decode(conn)
+func DecodeJSONLWith[T any](options ...Option) func(r io.Reader) (<-chan T, <-chan error) {
+	return func(r io.Reader) (<-chan T, <-chan error) {
+		return DecodeJSONL[T](r, options...)
+	}
+}
+
+// EncodeJSONL writes every element received from in to w as JSON lines, https://jsonlines.org, until in is closed.
+// Writes are buffered, and flushed whenever no further element is waiting on in, so slow streams are not held back.
+// It returns the first error from encoding or writing, after which in is no longer read, use DropAll to drain it
+// if needed.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	enriched := chanz.Map(events, enrich)
+//	if err := chanz.EncodeJSONL(out, enriched); err != nil {
+//	    log.Fatal(err)
+//	}
+func EncodeJSONL[T any](w io.Writer, in <-chan T, options ...Option) error {
+	var enc *json.Encoder
+	return writeTo(w, in, func(bw *bufio.Writer, t T) error {
+		if enc == nil {
+			enc = json.NewEncoder(bw)
+			enc.SetEscapeHTML(false)
+		}
+		return enc.Encode(t) // Encode terminates every value with a newline
+	}, options...)
+}
+
+// writeTo writes every element of in to w using write, through a bufio.Writer that is flushed whenever in has no
+// element waiting, and when writeTo returns
+func writeTo[A any](w io.Writer, in <-chan A, write func(bw *bufio.Writer, a A) error, options ...Option) (err error) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s.started()
+	defer func() {
+		if s.drain {
+			// The rest of in is discarded in the background, rather than holding up an error
+			go discard(s, in)
+		}
+		s.finished(nil)
+	}()
+
+	bw := bufio.NewWriter(w)
+	defer func() {
+		if ferr := bw.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+
+	for {
+		var e A
+		var ok bool
+		select {
+		case <-s.stop():
+			return nil
+		case e, ok = <-in:
+		}
+		if !ok {
+			return nil
+		}
+		s.observe(EventReceived, 0)
+		if err = write(bw, e); err != nil {
+			return err
+		}
+		s.observe(EventEmitted, 0)
+		if len(in) == 0 {
+			if err = bw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
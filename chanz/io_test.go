@@ -0,0 +1,180 @@
+package chanz
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestLines(t *testing.T) {
+	lines, errs := Lines(strings.NewReader("a\nbb\r\n\nccc"))
+	res := Collect(lines)
+	exp := []string{"a", "bb", "", "ccc"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if err := <-errs; err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestLinesError(t *testing.T) {
+	errBoom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(errBoom))
+	lines, errs := Lines(r)
+	res := Collect(lines)
+	exp := []string{"a", "b"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if err := <-errs; err != errBoom {
+		t.Logf("expected, %v, but got %v", errBoom, err)
+		t.Fail()
+	}
+	if _, ok := <-errs; ok {
+		t.Log("expected, error chan to be closed")
+		t.Fail()
+	}
+}
+
+func TestLinesDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lines, errs := Lines(strings.NewReader("a\nb\nc\n"), OpContext(ctx))
+	expectNext(t, lines, "a")
+	cancel()
+	DropAll(lines, false)
+	if err := <-errs; err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestScanReader(t *testing.T) {
+	words, _ := ScanReader(strings.NewReader(" the quick\n brown  fox "), bufio.ScanWords)
+	res := Collect(words)
+	exp := []string{"the", "quick", "brown", "fox"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestWriteLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLines(&buf, Generate("a", "b", "c")); err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+	if buf.String() != "a\nb\nc\n" {
+		t.Logf("expected, %q, but got %q", "a\nb\nc\n", buf.String())
+		t.Fail()
+	}
+}
+
+// errWriter fails every write after n bytes
+type errWriter struct {
+	n   int
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, w.err
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteLinesError(t *testing.T) {
+	errBoom := errors.New("boom")
+	in := make(chan string)
+	go func() {
+		in <- "a"
+		in <- "b"
+	}()
+	if err := WriteLines(&errWriter{n: 2, err: errBoom}, in); err != errBoom {
+		t.Logf("expected, %v, but got %v", errBoom, err)
+		t.Fail()
+	}
+}
+
+func TestWriteLinesFlush(t *testing.T) {
+	r, w := io.Pipe()
+	in := make(chan string)
+	go func() {
+		_ = WriteLines(w, in)
+		w.Close()
+	}()
+
+	lines, _ := Lines(r)
+	in <- "first"
+	expectNext(t, lines, "first") // flushed although in is still open
+	in <- "second"
+	close(in)
+	expectNext(t, lines, "second")
+	expectClosed(t, lines)
+}
+
+type jsonlRow struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDecodeJSONL(t *testing.T) {
+	in := "{\"id\":1,\"name\":\"a\"}\n\n  {\"id\":2,\"name\":\"b\"}  \n{\"id\":3,\"name\":\"c\"}"
+	rows, errs := DecodeJSONL[jsonlRow](strings.NewReader(in))
+	res := Collect(rows)
+	exp := []jsonlRow{{1, "a"}, {2, "b"}, {3, "c"}}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if err := <-errs; err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestDecodeJSONLError(t *testing.T) {
+	rows, errs := DecodeJSONL[jsonlRow](strings.NewReader("{\"id\":1}\n{\"id\":\n{\"id\":3}\n"))
+	res := Collect(rows)
+	exp := []jsonlRow{{ID: 1}}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	err := <-errs
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Logf("expected, error on line 2, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestEncodeJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeJSONL(&buf, Generate(jsonlRow{1, "a"}, jsonlRow{2, "<b>"}))
+	exp := "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"<b>\"}\n"
+	if err != nil || buf.String() != exp {
+		t.Logf("expected, %q, but got %q, %v", exp, buf.String(), err)
+		t.Fail()
+	}
+
+	rows, _ := DecodeJSONL[jsonlRow](&buf)
+	res := Collect(rows)
+	if !slicez.Equal(res, []jsonlRow{{1, "a"}, {2, "<b>"}}) {
+		t.Logf("expected, round trip, but got %v", res)
+		t.Fail()
+	}
+}
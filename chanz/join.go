@@ -0,0 +1,224 @@
+package chanz
+
+import (
+	"time"
+
+	"github.com/modfin/henry/mon"
+)
+
+// JoinMode decides what JoinByKey does with elements that expire without having been matched.
+type JoinMode int
+
+const (
+	// JoinInner drops unmatched elements, only pairs are emitted.
+	JoinInner JoinMode = iota
+	// JoinLeft emits unmatched left elements, paired with None, once they expire.
+	JoinLeft
+	// JoinOuter emits unmatched elements from both sides, paired with None, once they expire.
+	JoinOuter
+)
+
+// OpJoin creates an option that sets the JoinMode of JoinByKey. Default is JoinInner.
+func OpJoin(mode JoinMode) Option {
+	return func(s settings) settings {
+		s.join = mode
+		return s
+	}
+}
+
+// joinEntry is an element buffered by JoinByKey
+type joinEntry[A any, K comparable] struct {
+	val     A
+	key     K
+	at      time.Time
+	matched bool
+}
+
+// joinSide holds the buffered, not yet expired, elements of one side of JoinByKey
+type joinSide[A any, K comparable] struct {
+	queue []*joinEntry[A, K]       // in arrival order
+	byKey map[K][]*joinEntry[A, K] // in arrival order per key
+}
+
+func (s *joinSide[A, K]) add(e *joinEntry[A, K]) {
+	s.queue = append(s.queue, e)
+	s.byKey[e.key] = append(s.byKey[e.key], e)
+}
+
+// expire removes and returns the elements that arrived at or before the cutoff
+func (s *joinSide[A, K]) expire(cutoff time.Time) []*joinEntry[A, K] {
+	var i int
+	for i < len(s.queue) && !s.queue[i].at.After(cutoff) {
+		e := s.queue[i]
+		if rest := s.byKey[e.key][1:]; len(rest) > 0 {
+			s.byKey[e.key] = rest
+		} else {
+			delete(s.byKey, e.key)
+		}
+		i++
+	}
+	expired := s.queue[:i]
+	s.queue = s.queue[i:]
+	return expired
+}
+
+// JoinByKey takes two chans and returns a chan of joined elements. Elements from each side are buffered for the
+// duration of window after they were received, and whenever an element arrives it is joined with every buffered
+// element of the other side having the same key. This makes the join insensitive to the order in which the two
+// sides arrive, as long as matching elements arrive within window of each other.
+// The joiner is called with Some for both sides when a pair is found. With OpJoin(JoinLeft) or OpJoin(JoinOuter)
+// elements that expire without having been matched are passed to the joiner with None for the other side,
+// this is also done for the elements still buffered once both inputs are closed.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once both "left" and "right", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	joined := chanz.JoinByKey(orders, fills,
+//	    func(o Order) string { return o.ID },
+//	    func(f Fill) string { return f.OrderID },
+//	    time.Minute,
+//	    func(o mon.Option[Order], f mon.Option[Fill]) Execution {
+//	        return Execution{Order: o.OrEmpty(), Fill: f.OrEmpty(), Filled: f.Some()}
+//	    },
+//	    chanz.OpJoin(chanz.JoinLeft), // also emit orders that had no fill within a minute
+//	)
+func JoinByKey[L any, R any, K comparable, C any](left <-chan L, right <-chan R, keyL func(l L) K, keyR func(r R) K, window time.Duration, joiner func(l mon.Option[L], r mon.Option[R]) C, options ...Option) <-chan C {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan C, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, left)
+			discard(s, right)
+		})
+
+		clock := s.getClock()
+		lefts := &joinSide[L, K]{byKey: map[K][]*joinEntry[L, K]{}}
+		rights := &joinSide[R, K]{byKey: map[K][]*joinEntry[R, K]{}}
+
+		// emit joins l and r and puts the result on out, it returns false if the stage should stop
+		emit := func(l mon.Option[L], r mon.Option[R]) bool {
+			c, ok := call(s, func(l mon.Option[L]) C { return joiner(l, r) }, l)
+			if !ok {
+				return !s.recoverStop
+			}
+			return send(s, out, c)
+		}
+		expire := func(cutoff time.Time) bool {
+			for _, e := range lefts.expire(cutoff) {
+				if !e.matched && (s.join == JoinLeft || s.join == JoinOuter) {
+					if !emit(mon.Some(e.val), mon.None[R]()) {
+						return false
+					}
+				}
+			}
+			for _, e := range rights.expire(cutoff) {
+				if !e.matched && s.join == JoinOuter {
+					if !emit(mon.None[L](), mon.Some(e.val)) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		var timer Timer
+		var timeout <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		schedule := func() {
+			if timer != nil {
+				timer.Stop()
+			}
+			timeout = nil
+			var next time.Time
+			if len(lefts.queue) > 0 {
+				next = lefts.queue[0].at
+			}
+			if len(rights.queue) > 0 && (next.IsZero() || rights.queue[0].at.Before(next)) {
+				next = rights.queue[0].at
+			}
+			if next.IsZero() {
+				return
+			}
+			timer = clock.NewTimer(next.Add(window).Sub(clock.Now()))
+			timeout = timer.C()
+		}
+
+		for left != nil || right != nil {
+			select {
+			case <-s.stop():
+				return
+			case l, ok := <-left:
+				if !ok {
+					left = nil
+					continue
+				}
+				s.observe(EventReceived, 0)
+				key, ok := call(s, keyL, l)
+				if !ok {
+					if s.recoverStop {
+						return
+					}
+					continue
+				}
+				now := clock.Now()
+				if !expire(now.Add(-window)) {
+					return
+				}
+				e := &joinEntry[L, K]{val: l, key: key, at: now}
+				for _, r := range rights.byKey[e.key] {
+					e.matched, r.matched = true, true
+					if !emit(mon.Some(l), mon.Some(r.val)) {
+						return
+					}
+				}
+				lefts.add(e)
+			case r, ok := <-right:
+				if !ok {
+					right = nil
+					continue
+				}
+				s.observe(EventReceived, 0)
+				key, ok := call(s, keyR, r)
+				if !ok {
+					if s.recoverStop {
+						return
+					}
+					continue
+				}
+				now := clock.Now()
+				if !expire(now.Add(-window)) {
+					return
+				}
+				e := &joinEntry[R, K]{val: r, key: key, at: now}
+				for _, l := range lefts.byKey[e.key] {
+					e.matched, l.matched = true, true
+					if !emit(mon.Some(l.val), mon.Some(r)) {
+						return
+					}
+				}
+				rights.add(e)
+			case <-timeout:
+				if !expire(clock.Now().Add(-window)) {
+					return
+				}
+			}
+			schedule()
+		}
+
+		// Both sides are closed, nothing more can be matched
+		expire(clock.Now().Add(window))
+	}()
+	return out
+}
@@ -0,0 +1,105 @@
+package chanz_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+type order struct {
+	id  string
+	qty int
+}
+
+type fill struct {
+	orderID string
+	price   int
+}
+
+func joinOrderFill(o mon.Option[order], f mon.Option[fill]) string {
+	switch {
+	case o.Some() && f.Some():
+		return fmt.Sprintf("%s:%d@%d", o.MustGet().id, o.MustGet().qty, f.MustGet().price)
+	case o.Some():
+		return fmt.Sprintf("%s:%d@-", o.MustGet().id, o.MustGet().qty)
+	default:
+		return fmt.Sprintf("-@%d", f.MustGet().price)
+	}
+}
+
+func TestJoinByKey(t *testing.T) {
+	orders := chanz.Generate(order{"a", 1}, order{"b", 2}, order{"c", 3})
+	fills := chanz.Generate(fill{"c", 30}, fill{"a", 10}, fill{"a", 11}, fill{"x", 99})
+
+	res := chanz.Collect(chanz.JoinByKey(orders, fills, func(o order) string { return o.id }, func(f fill) string { return f.orderID }, time.Hour, joinOrderFill))
+
+	exp := []string{"a:1@10", "a:1@11", "c:3@30"}
+	if !slicez.Equal(exp, slicez.Sort(res)) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestJoinByKeyOuter(t *testing.T) {
+	for _, tc := range []struct {
+		mode chanz.JoinMode
+		exp  []string
+	}{
+		{chanz.JoinInner, []string{"a:1@10"}},
+		{chanz.JoinLeft, []string{"a:1@10", "b:2@-"}},
+		{chanz.JoinOuter, []string{"-@99", "a:1@10", "b:2@-"}},
+	} {
+		orders := chanz.Generate(order{"a", 1}, order{"b", 2})
+		fills := chanz.Generate(fill{"a", 10}, fill{"x", 99})
+		res := chanz.Collect(chanz.JoinByKey(orders, fills,
+			func(o order) string { return o.id }, func(f fill) string { return f.orderID }, time.Hour, joinOrderFill, chanz.OpJoin(tc.mode)))
+		if !slicez.Equal(tc.exp, slicez.Sort(res)) {
+			t.Logf("expected, %v, but got %v, for mode %d", tc.exp, res, tc.mode)
+			t.Fail()
+		}
+	}
+}
+
+func TestJoinByKeyWindow(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	orders := make(chan order)
+	fills := make(chan fill)
+	joined := chanz.JoinByKey(orders, fills, func(o order) string { return o.id }, func(f fill) string { return f.orderID },
+		time.Minute, joinOrderFill, chanz.OpClock(clock), chanz.OpJoin(chanz.JoinLeft))
+
+	// Fill arriving before its order is still matched
+	fills <- fill{"a", 10}
+	clock.Advance(30 * time.Second)
+	orders <- order{"a", 1}
+	chanztest.ExpectSequence(t, joined, time.Second, "a:1@10")
+
+	// Order expires unmatched after the window
+	orders <- order{"b", 2}
+	clock.BlockUntilArmed(t, 3)
+	clock.Advance(59 * time.Second)
+	chanztest.ExpectNothing(t, joined, chanztest.ShortWait)
+	clock.BlockUntilArmed(t, 4) // re-armed once the fill expired
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, joined, time.Second, "b:2@-")
+
+	// A fill arriving after the order expired is not matched
+	fills <- fill{"b", 20}
+
+	close(orders)
+	close(fills)
+	chanztest.ExpectClosed(t, joined, time.Second)
+}
+
+func TestJoinByKeyContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	joined := chanz.JoinByKey(make(chan order), make(chan fill), func(o order) string { return o.id }, func(f fill) string { return f.orderID },
+		time.Minute, joinOrderFill, chanz.OpContext(ctx))
+	cancel()
+	chanztest.ExpectClosed(t, joined, time.Second)
+}
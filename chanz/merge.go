@@ -0,0 +1,119 @@
+package chanz
+
+import (
+	"container/heap"
+)
+
+// MergeSorted merges multiple channels, each already ordered by less, into one ordered output channel.
+// It is a heap based k-way merge. Since the next element can't be decided until every open input has one waiting,
+// the output advances at the pace of the slowest input. Elements that are equal keep the order of the inputs.
+// Output closes when all input channels are closed.
+//
+// Example:
+//
+//	ch1 := chanz.Generate(1, 4, 7)
+//	ch2 := chanz.Generate(2, 5, 8)
+//	ch3 := chanz.Generate(3, 6, 9)
+//	merged := chanz.MergeSorted(compare.Less[int], ch1, ch2, ch3)
+//	result := chanz.Collect(merged)
+//	// result = []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+func MergeSorted[A any](less func(a, b A) bool, cs ...<-chan A) <-chan A {
+	return MergeSortedWith[A]()(less, cs...)
+}
+
+// MergeSortedWith returns a configured MergeSorted function closure.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once all "cs", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	merger := chanz.MergeSortedWith[Tick](chanz.OpContext(ctx))
+//	ticks := merger(func(a, b Tick) bool { return a.Time.Before(b.Time) }, nasdaq, nyse, lse)
+func MergeSortedWith[A any](options ...Option) func(less func(a, b A) bool, cs ...<-chan A) <-chan A {
+	return func(less func(a, b A) bool, cs ...<-chan A) <-chan A {
+		var s settings
+		for _, o := range options {
+			s = o(s)
+		}
+
+		out := make(chan A, s.buffer)
+		s.started()
+		go func() {
+			defer s.finished(func() { close(out) })
+
+			h := &mergeHeap[A]{less: less}
+			for i, c := range cs {
+				select {
+				case <-s.stop():
+					return
+				case e, ok := <-c:
+					if ok {
+						s.observe(EventReceived, 0)
+						h.heads = append(h.heads, mergeHead[A]{val: e, src: i})
+					}
+				}
+			}
+			heap.Init(h)
+
+			for h.Len() > 0 {
+				head := h.heads[0]
+				if !send(s, out, head.val) {
+					return
+				}
+
+				select {
+				case <-s.stop():
+					return
+				case e, ok := <-cs[head.src]:
+					if !ok {
+						heap.Pop(h)
+						continue
+					}
+					s.observe(EventReceived, 0)
+					h.heads[0].val = e
+					heap.Fix(h, 0)
+				}
+			}
+		}()
+		return out
+	}
+}
+
+type mergeHead[A any] struct {
+	val A
+	src int
+}
+
+// mergeHeap implements heap.Interface for the current head of every open input of MergeSorted
+type mergeHeap[A any] struct {
+	heads []mergeHead[A]
+	less  func(a, b A) bool
+}
+
+func (h *mergeHeap[A]) Len() int {
+	return len(h.heads)
+}
+
+func (h *mergeHeap[A]) Less(i, j int) bool {
+	if h.less(h.heads[i].val, h.heads[j].val) {
+		return true
+	}
+	if h.less(h.heads[j].val, h.heads[i].val) {
+		return false
+	}
+	return h.heads[i].src < h.heads[j].src
+}
+
+func (h *mergeHeap[A]) Swap(i, j int) {
+	h.heads[i], h.heads[j] = h.heads[j], h.heads[i]
+}
+
+func (h *mergeHeap[A]) Push(x any) {
+	h.heads = append(h.heads, x.(mergeHead[A]))
+}
+
+func (h *mergeHeap[A]) Pop() any {
+	last := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return last
+}
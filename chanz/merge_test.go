@@ -0,0 +1,69 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/compare"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestMergeSorted(t *testing.T) {
+	ch1 := Generate(1, 4, 7, 10)
+	ch2 := Generate(2, 5, 8)
+	ch3 := Generate(3, 6, 9)
+	ch4 := Generate[int]()
+
+	res := Collect(MergeSorted(compare.Less[int], ch1, ch2, ch3, ch4))
+	exp := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestMergeSortedStable(t *testing.T) {
+	type tick struct {
+		at  int
+		src string
+	}
+	a := Generate(tick{1, "a"}, tick{2, "a"})
+	b := Generate(tick{1, "b"}, tick{2, "b"})
+
+	res := Collect(MergeSorted(func(x, y tick) bool { return x.at < y.at }, a, b))
+	exp := []tick{{1, "a"}, {1, "b"}, {2, "a"}, {2, "b"}}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestMergeSortedEmpty(t *testing.T) {
+	res := Collect(MergeSorted(compare.Less[int]))
+	if len(res) != 0 {
+		t.Errorf("expected empty result, got %v", res)
+	}
+}
+
+func TestMergeSortedWith(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	slow := make(chan int)
+	merger := MergeSortedWith[int](OpContext(ctx), OpBuffer(1))
+	merged := merger(compare.Less[int], Generate(2, 3), slow)
+
+	slow <- 1
+	expectNext(t, merged, 1)
+	// slow is still open and might yield something smaller than 2
+	expectNothing(t, merged)
+
+	cancel()
+	select {
+	case _, ok := <-merged:
+		if ok {
+			t.Error("expected output to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected output to close after cancel")
+	}
+}
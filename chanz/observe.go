@@ -0,0 +1,246 @@
+package chanz
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventKind is the kind of Event a stage reports to its Observer.
+type EventKind int
+
+const (
+	// EventReceived is reported when a stage has received an element from one of its inputs.
+	EventReceived EventKind = iota
+	// EventEmitted is reported when a stage has put an element on one of its outputs.
+	EventEmitted
+	// EventBlocked is reported when a stage had to wait for a consumer before it could emit an element.
+	// The time spent waiting is in Event.Duration.
+	EventBlocked
+	// EventClosed is reported when a stage stops since its input is exhausted.
+	EventClosed
+	// EventCancelled is reported when a stage stops since the "done" channel or the context.Done is closed.
+	EventCancelled
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventReceived:
+		return "received"
+	case EventEmitted:
+		return "emitted"
+	case EventBlocked:
+		return "blocked"
+	case EventClosed:
+		return "closed"
+	case EventCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// Event is reported by a stage to its Observer.
+type Event struct {
+	Stage    string        // The name given in OpObserver
+	Kind     EventKind     // What happened
+	Duration time.Duration // Time spent blocked, only set for EventBlocked
+}
+
+// Observer receives events from the stages it is supplied to with OpObserver.
+// Observe is called from the goroutines of the stages, concurrently if the observer is shared between stages,
+// and it is called inline, so it should be fast.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc is a func that implements Observer.
+//
+// Example:
+//
+//	logger := chanz.ObserverFunc(func(e chanz.Event) {
+//	    if e.Kind == chanz.EventBlocked && e.Duration > time.Second {
+//	        log.Printf("%s blocked for %v", e.Stage, e.Duration)
+//	    }
+//	})
+type ObserverFunc func(e Event)
+
+// Observe calls f(e)
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// OpObserver creates an option that makes a stage report events to observer, using name as Event.Stage.
+// Supplying the same observer to every stage of a pipeline, with different names, shows which stage is backing up.
+// Blocked durations are measured by the clock supplied with OpClock, default is the wall clock.
+// Distribute does not report EventBlocked, since its Strategy, rather than the stage, puts elements on the outputs.
+func OpObserver(name string, observer Observer) Option {
+	return func(s settings) settings {
+		s.name = name
+		s.observer = observer
+		return s
+	}
+}
+
+// observe reports an event to the observer, if any
+func (s settings) observe(kind EventKind, d time.Duration) {
+	if s.observer == nil {
+		return
+	}
+	s.observer.Observe(Event{Stage: s.name, Kind: kind, Duration: d})
+}
+
+// finished reports that a stage has stopped, as cancelled if it was stopped and otherwise as closed, calls cleanup,
+// if not nil, which closes the outputs of the stage and discards what is left of its inputs, and then removes the
+// stage from its group and tracker, so that a stage is not seen as stopped until its outputs are closed and its
+// inputs released.
+func (s settings) finished(cleanup func()) {
+	if s.group != nil {
+		defer s.group.add(-1)
+	}
+	if s.tracked != nil {
+		defer s.tracked.tracker.remove(s.tracked.id)
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if s.observer == nil {
+		return
+	}
+	select {
+	case <-s.stop():
+		s.observe(EventCancelled, 0)
+	default:
+		s.observe(EventClosed, 0)
+	}
+}
+
+// send puts a on out, unless the stage is stopped first, in which case it returns false.
+// The emitted element, and any time spent waiting for a consumer, is reported to the observer.
+func send[A any](s settings, out chan<- A, a A) bool {
+	if s.observer == nil {
+		select {
+		case <-s.stop():
+			return false
+		case out <- a:
+			return true
+		}
+	}
+
+	select {
+	case <-s.stop():
+		return false
+	case out <- a:
+		s.observe(EventEmitted, 0)
+		return true
+	default:
+	}
+
+	clock := s.getClock()
+	start := clock.Now()
+	select {
+	case <-s.stop():
+		s.observe(EventBlocked, clock.Now().Sub(start))
+		return false
+	case out <- a:
+		s.observe(EventBlocked, clock.Now().Sub(start))
+		s.observe(EventEmitted, 0)
+		return true
+	}
+}
+
+// StageStats are the counters kept by Stats for one stage.
+type StageStats struct {
+	Received  int64         `json:"received"`
+	Emitted   int64         `json:"emitted"`
+	Blocked   time.Duration `json:"blocked_ns"` // Total time spent waiting for consumers
+	Closed    int64         `json:"closed"`     // Number of stage goroutines stopped since input was exhausted
+	Cancelled int64         `json:"cancelled"`  // Number of stage goroutines stopped by done or context
+}
+
+// Stats is an Observer that keeps StageStats per stage name in memory.
+// It implements expvar.Var, so it can be exported on /debug/vars with expvar.Publish.
+//
+// Example:
+//
+//	stats := chanz.NewStats()
+//	expvar.Publish("pipeline", stats)
+//
+//	parsed := chanz.Map(lines, parse, chanz.OpObserver("parse", stats))
+//	valid := chanz.Filter(parsed, validate, chanz.OpObserver("validate", stats))
+//	merged := chanz.FanInWith[Record](chanz.OpObserver("merge", stats))(valid, backfill)
+//	// stats.Stage("parse").Blocked growing fast means that validate is not keeping up
+type Stats struct {
+	mu     sync.Mutex
+	stages map[string]*StageStats
+}
+
+// NewStats returns an empty Stats.
+func NewStats() *Stats {
+	return &Stats{stages: map[string]*StageStats{}}
+}
+
+// Observe updates the StageStats of e.Stage.
+func (s *Stats) Observe(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.stages[e.Stage]
+	if !ok {
+		st = &StageStats{}
+		s.stages[e.Stage] = st
+	}
+	switch e.Kind {
+	case EventReceived:
+		st.Received++
+	case EventEmitted:
+		st.Emitted++
+	case EventBlocked:
+		st.Blocked += e.Duration
+	case EventClosed:
+		st.Closed++
+	case EventCancelled:
+		st.Cancelled++
+	}
+}
+
+// Stage returns the StageStats of the named stage.
+func (s *Stats) Stage(name string) StageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.stages[name]; ok {
+		return *st
+	}
+	return StageStats{}
+}
+
+// Stages returns the names of all stages that have reported events, sorted.
+func (s *Stats) Stages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.stages))
+	for name := range s.stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot returns a copy of the StageStats of all stages.
+func (s *Stats) Snapshot() map[string]StageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[string]StageStats, len(s.stages))
+	for name, st := range s.stages {
+		snapshot[name] = *st
+	}
+	return snapshot
+}
+
+// String returns the Snapshot as JSON, which makes Stats an expvar.Var.
+func (s *Stats) String() string {
+	b, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
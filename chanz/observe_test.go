@@ -0,0 +1,182 @@
+package chanz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestObserverPipeline(t *testing.T) {
+	stats := NewStats()
+	mapped := Map(Generate(1, 2, 3, 4, 5, 6), func(i int) int { return i * 10 }, OpObserver("map", stats))
+	filtered := Filter(mapped, func(i int) bool { return i > 30 }, OpObserver("filter", stats))
+	res := Collect(filtered)
+
+	exp := []int{40, 50, 60}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+
+	expStages := []string{"filter", "map"}
+	if !slicez.Equal(stats.Stages(), expStages) {
+		t.Logf("expected, %v, but got %v", expStages, stats.Stages())
+		t.Fail()
+	}
+
+	m := stats.Stage("map")
+	if m.Received != 6 || m.Emitted != 6 || m.Closed != 1 || m.Cancelled != 0 {
+		t.Logf("expected, 6 received, 6 emitted and closed once, but got %+v", m)
+		t.Fail()
+	}
+	f := stats.Stage("filter")
+	if f.Received != 6 || f.Emitted != 3 || f.Closed != 1 {
+		t.Logf("expected, 6 received, 3 emitted and closed once, but got %+v", f)
+		t.Fail()
+	}
+}
+
+func TestObserverBlocked(t *testing.T) {
+	stats := NewStats()
+	mapped := Map(Generate(1, 2), func(i int) int { return i }, OpObserver("slow consumer", stats))
+
+	for range mapped {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	st := stats.Stage("slow consumer")
+	if st.Blocked < 10*time.Millisecond {
+		t.Logf("expected, at least 10ms blocked, but got %v", st.Blocked)
+		t.Fail()
+	}
+}
+
+func TestObserverCancelled(t *testing.T) {
+	stats := NewStats()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	mapped := Map(in, func(i int) int { return i }, OpContext(ctx), OpObserver("map", stats))
+
+	<-mapped
+	<-mapped
+	cancel() // the third element is never read, so the stage can only stop by being cancelled
+
+	for i := 0; i < 100 && stats.Stage("map").Cancelled == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	expectClosed(t, mapped)
+
+	st := stats.Stage("map")
+	if st.Cancelled != 1 || st.Closed != 0 || st.Received != 3 || st.Emitted != 2 {
+		t.Logf("expected, 3 received, 2 emitted and cancelled once, but got %+v", st)
+		t.Fail()
+	}
+}
+
+func TestObserverFunc(t *testing.T) {
+	var mu sync.Mutex
+	kinds := map[EventKind]int{}
+	observer := ObserverFunc(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Stage != "workers" {
+			t.Logf("expected, workers, but got %v", e.Stage)
+			t.Fail()
+		}
+		kinds[e.Kind]++
+	})
+
+	res := Collect(ParallelMap(Generate(1, 2, 3, 4), func(i int) int { return i }, 2, OpObserver("workers", observer)))
+	if len(res) != 4 {
+		t.Logf("expected, 4 results, but got %v", res)
+		t.Fail()
+	}
+	if kinds[EventReceived] != 4 || kinds[EventEmitted] != 4 || kinds[EventClosed] != 1 {
+		t.Logf("expected, 4 received, 4 emitted and closed once, but got %v", kinds)
+		t.Fail()
+	}
+}
+
+func TestStatsExpvar(t *testing.T) {
+	stats := NewStats()
+	var _ expvar.Var = stats
+
+	stats.Observe(Event{Stage: "a", Kind: EventReceived})
+	stats.Observe(Event{Stage: "a", Kind: EventBlocked, Duration: time.Second})
+	stats.Observe(Event{Stage: "b", Kind: EventCancelled})
+
+	var res map[string]StageStats
+	if err := json.Unmarshal([]byte(stats.String()), &res); err != nil {
+		t.Logf("expected, valid json, but got %v", err)
+		t.FailNow()
+	}
+	exp := map[string]StageStats{
+		"a": {Received: 1, Blocked: time.Second},
+		"b": {Cancelled: 1},
+	}
+	if len(res) != len(exp) || res["a"] != exp["a"] || res["b"] != exp["b"] {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestEventKindString(t *testing.T) {
+	res := slicez.Map([]EventKind{EventReceived, EventEmitted, EventBlocked, EventClosed, EventCancelled}, EventKind.String)
+	exp := []string{"received", "emitted", "blocked", "closed", "cancelled"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestObserverJoinByKey(t *testing.T) {
+	stats := NewStats()
+	key := func(i int) int { return i }
+	joined := JoinByKey(Generate(1, 2), Generate(2, 1), key, key, time.Hour, func(l mon.Option[int], r mon.Option[int]) int {
+		return l.OrEmpty() + r.OrEmpty()
+	}, OpObserver("join", stats))
+
+	var res []int
+	for e := range joined {
+		time.Sleep(20 * time.Millisecond)
+		res = append(res, e)
+	}
+	if !slicez.Equal(slicez.Sort(res), []int{2, 4}) {
+		t.Logf("expected, [2 4], but got %v", res)
+		t.Fail()
+	}
+
+	st := stats.Stage("join")
+	if st.Received != 4 || st.Emitted != 2 || st.Closed != 1 || st.Blocked < 10*time.Millisecond {
+		t.Logf("expected, 4 received, 2 emitted, blocked and closed once, but got %+v", st)
+		t.Fail()
+	}
+}
+
+func TestObserverWriteLines(t *testing.T) {
+	stats := NewStats()
+	var buf bytes.Buffer
+	if err := WriteLines(&buf, Generate("a", "b"), OpObserver("write", stats)); err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+
+	// The stage is reported as closed by the time WriteLines returns
+	st := stats.Stage("write")
+	if st.Closed != 1 {
+		t.Logf("expected, closed once, but got %+v", st)
+		t.Fail()
+	}
+}
@@ -0,0 +1,191 @@
+package chanz
+
+import "sync"
+
+// OpOrdered creates an option that makes concurrent stages, such as ParallelMap,
+// emit results in the same order as their input was received.
+// Default is to emit results as soon as they are ready.
+func OpOrdered() Option {
+	return func(s settings) settings {
+		s.ordered = true
+		return s
+	}
+}
+
+// ParallelMap will take a chan, in, and execute mapper on the elements using a pool of workers,
+// putting the results on to the return chan.
+// Unlike Map, a slow mapper does not serialize the pipeline since up to workers elements are mapped concurrently.
+// Results are emitted as they are finished, unless OpOrdered is supplied, in which case a reorder buffer
+// of at most workers elements is used to emit results in input order.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	input := chanz.Generate(1, 2, 3, 4, 5)
+//	squared := chanz.ParallelMap(input, func(n int) int { return n * n }, 3, chanz.OpOrdered())
+//	result := chanz.Collect(squared)
+//	// result = []int{1, 4, 9, 16, 25}
+func ParallelMap[A any, B any](in <-chan A, mapper func(a A) B, workers int, options ...Option) <-chan B {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	halt := func() {}
+	if s.recoverStop {
+		// A panic in one worker stops all of them
+		stop := make(chan struct{})
+		var once sync.Once
+		halt = func() { once.Do(func() { close(stop) }) }
+		s = s.withAbort(stop)
+		s.onPanic = func(handler func(p any)) func(p any) {
+			return func(p any) {
+				halt()
+				if handler != nil {
+					handler(p)
+				}
+			}
+		}(s.onPanic)
+	}
+
+	out := make(chan B, s.buffer)
+	if s.ordered {
+		s.started()
+		go func() {
+			defer halt()
+			parallelMapOrdered(in, out, mapper, workers, s)
+		}()
+		return out
+	}
+
+	var wg sync.WaitGroup
+	worker := func() {
+		defer wg.Done()
+		for {
+			var e A
+			var ok bool
+			select {
+			case <-s.stop():
+				return
+			case e, ok = <-in:
+			}
+			if !ok {
+				return
+			}
+			s.observe(EventReceived, 0)
+			b, ok := call(s, mapper, e)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			if !send(s, out, b) {
+				return
+			}
+		}
+	}
+	s.started()
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+	go func() {
+		defer halt()
+		wg.Wait()
+		s.finished(func() { close(out) })
+	}()
+	return out
+}
+
+type parallelJob[A any, B any] struct {
+	val    A
+	result chan B
+}
+
+// parallelMapOrdered dispatches every element to the worker pool together with a result slot.
+// The slots are queued in input order, and since the queue is bounded by the number of workers,
+// so is the number of results waiting to be emitted.
+func parallelMapOrdered[A any, B any](in <-chan A, out chan<- B, mapper func(a A) B, workers int, s settings) {
+	jobs := make(chan parallelJob[A, B])
+	queue := make(chan chan B, workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+		for {
+			var e A
+			var ok bool
+			select {
+			case <-s.stop():
+				return
+			case e, ok = <-in:
+			}
+			if !ok {
+				return
+			}
+			s.observe(EventReceived, 0)
+			result := make(chan B, 1)
+			select {
+			case <-s.stop():
+				return
+			case queue <- result:
+			}
+			select {
+			case <-s.stop():
+				return
+			case jobs <- parallelJob[A, B]{val: e, result: result}:
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				b, ok := call(s, mapper, j.val)
+				if !ok {
+					close(j.result)
+					continue
+				}
+				j.result <- b
+			}
+		}()
+	}
+
+	defer s.finished(func() { close(out) })
+	for result := range queue {
+		var b B
+		var ok bool
+		select {
+		case <-s.stop():
+			return
+		case b, ok = <-result:
+		}
+		if !ok {
+			if s.recoverStop {
+				return
+			}
+			continue
+		}
+		if !send(s, out, b) {
+			return
+		}
+	}
+}
+
+// ParallelMapWith returns a configured ParallelMap function closure.
+// Allows creating reusable worker pools with preset options.
+//
+// Example:
+//
+//	fetcher := chanz.ParallelMapWith[string, int](chanz.OpOrdered(), chanz.OpBuffer(10))
+//	sizes := fetcher(urls, fetchSize, 8)
+func ParallelMapWith[A any, B any](options ...Option) func(in <-chan A, mapper func(a A) B, workers int) <-chan B {
+	return func(in <-chan A, mapper func(a A) B, workers int) <-chan B {
+		return ParallelMap(in, mapper, workers, options...)
+	}
+}
@@ -0,0 +1,110 @@
+package chanz
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestParallelMap(t *testing.T) {
+	in := Generate(1, 2, 3, 4, 5, 6, 7, 8, 9)
+	res := Collect(ParallelMap(in, func(a int) int {
+		return a * a
+	}, 3))
+
+	exp := []int{1, 4, 9, 16, 25, 36, 49, 64, 81}
+	if !slicez.Equal(exp, slicez.Sort(res)) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	in := Generate(5, 4, 3, 2, 1, 0)
+	res := Collect(ParallelMap(in, func(a int) int {
+		time.Sleep(time.Duration(a) * 5 * time.Millisecond)
+		return a * 10
+	}, 4, OpOrdered()))
+
+	exp := []int{50, 40, 30, 20, 10, 0}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestParallelMapConcurrency(t *testing.T) {
+	var running, peak int32
+	in := Generate(1, 2, 3, 4, 5, 6, 7, 8)
+	res := Collect(ParallelMap(in, func(a int) int {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return a
+	}, 4))
+
+	if len(res) != 8 {
+		t.Errorf("expected 8 elements, got %d", len(res))
+	}
+	if peak > 4 {
+		t.Errorf("expected at most 4 concurrent workers, got %d", peak)
+	}
+	if peak < 2 {
+		t.Errorf("expected workers to run concurrently, peak was %d", peak)
+	}
+}
+
+func TestParallelMapContext(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := Generator(func(yield func(int)) {
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					yield(i)
+				}
+			}
+		}, OpContext(ctx))
+
+		opts := []Option{OpContext(ctx)}
+		if ordered {
+			opts = append(opts, OpOrdered())
+		}
+		out := ParallelMap(in, func(a int) int { return a }, 2, opts...)
+		<-out
+		cancel()
+
+		closed := make(chan struct{})
+		go func() {
+			DropAll(out, false)
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Errorf("expected output to close after cancel, ordered=%v", ordered)
+		}
+	}
+}
+
+func TestParallelMapWith(t *testing.T) {
+	mapper := ParallelMapWith[int, int](OpOrdered(), OpBuffer(2))
+	res := Collect(mapper(Generate(1, 2, 3), func(a int) int { return a + 1 }, 2))
+
+	exp := []int{2, 3, 4}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
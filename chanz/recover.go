@@ -0,0 +1,82 @@
+package chanz
+
+import (
+	"fmt"
+)
+
+// OpRecover creates an option that makes stages recover from panics in the functions supplied to them, such as
+// the mapper of Map or the predicate of Filter. The recovered value is passed to handler, if not nil, and the element
+// that caused the panic is skipped. Error aware stages, such as MapErr, emit a PanicError instead of skipping.
+// It does not apply to the less func of MergeSorted, a panic while ordering elements can not be skipped past.
+// Default is to let the panic crash the program.
+func OpRecover(handler func(p any)) Option {
+	return func(s settings) settings {
+		s.recover = true
+		s.recoverStop = false
+		s.onPanic = handler
+		return s
+	}
+}
+
+// OpRecoverStop creates an option that makes stages recover from panics in the functions supplied to them.
+// Like OpRecover, but instead of skipping the element the stage stops, closing its output.
+func OpRecoverStop(handler func(p any)) Option {
+	return func(s settings) settings {
+		s.recover = true
+		s.recoverStop = true
+		s.onPanic = handler
+		return s
+	}
+}
+
+// PanicError is the error emitted by error aware stages, such as MapErr, when recovering from a panic.
+type PanicError struct {
+	Value any // The value recovered from the panic
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("chanz: recovered from panic: %v", e.Value)
+}
+
+// call applies fn to a. If fn panics and the settings asks for recovery, the panic is reported to the recover handler
+// and ok is false, in which case the stage should skip the element, or stop if s.recoverStop is set.
+func call[A any, B any](s settings, fn func(a A) B, a A) (b B, ok bool) {
+	if !s.recover {
+		return fn(a), true
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			if s.onPanic != nil {
+				s.onPanic(p)
+			}
+			ok = false
+		}
+	}()
+	return fn(a), true
+}
+
+// try runs fn, recovering from a panic like call does. It returns false if fn panicked.
+func try(s settings, fn func()) bool {
+	_, ok := call(s, func(struct{}) struct{} {
+		fn()
+		return struct{}{}
+	}, struct{}{})
+	return ok
+}
+
+// callErr applies fn to a. If fn panics and the settings asks for recovery, the panic is reported to the recover
+// handler and returned as a PanicError.
+func callErr[A any, B any](s settings, fn func(a A) (B, error), a A) (b B, err error) {
+	if !s.recover {
+		return fn(a)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			if s.onPanic != nil {
+				s.onPanic(p)
+			}
+			err = PanicError{Value: p}
+		}
+	}()
+	return fn(a)
+}
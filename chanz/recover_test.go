@@ -0,0 +1,308 @@
+package chanz
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestMapRecover(t *testing.T) {
+	var panics []any
+	res := Collect(Map(Generate(1, 2, 3, 4), func(i int) int {
+		if i == 2 {
+			panic("bad record")
+		}
+		return i * 10
+	}, OpRecover(func(p any) { panics = append(panics, p) })))
+
+	exp := []int{10, 30, 40}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if len(panics) != 1 || panics[0] != "bad record" {
+		t.Logf("expected, [bad record], but got %v", panics)
+		t.Fail()
+	}
+}
+
+func TestMapRecoverStop(t *testing.T) {
+	var panics int
+	res := Collect(Map(Generate(1, 2, 3, 4), func(i int) int {
+		if i == 2 {
+			panic("bad record")
+		}
+		return i * 10
+	}, OpRecoverStop(func(p any) { panics++ })))
+
+	exp := []int{10}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if panics != 1 {
+		t.Logf("expected, 1, but got %v", panics)
+		t.Fail()
+	}
+}
+
+func TestFilterRecover(t *testing.T) {
+	res := Collect(Filter(Generate(1, 2, 3, 4), func(i int) bool {
+		if i == 3 {
+			panic("bad record")
+		}
+		return i%2 == 0 || i == 1
+	}, OpRecover(nil)))
+
+	exp := []int{1, 2, 4}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestPeekRecover(t *testing.T) {
+	var seen []int
+	res := Collect(Peek(Generate(1, 2, 3), func(i int) {
+		if i == 1 {
+			var m map[string]int
+			m["boom"] = i
+		}
+		seen = append(seen, i)
+	}, OpRecover(nil)))
+
+	exp := []int{2, 3}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if !slicez.Equal(seen, exp) {
+		t.Logf("expected, %v, but got %v", exp, seen)
+		t.Fail()
+	}
+}
+
+func TestGeneratorRecover(t *testing.T) {
+	res := Collect(Generator(func(yield func(int)) {
+		yield(1)
+		yield(2)
+		panic("source failed")
+	}, OpRecover(nil)))
+
+	exp := []int{1, 2}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestMapErrRecover(t *testing.T) {
+	res := Collect(MapErr(Generate(1, 2, 3), func(i int) (int, error) {
+		if i == 2 {
+			panic("bad record")
+		}
+		return i, nil
+	}, OpRecover(nil)))
+
+	if len(res) != 3 {
+		t.Logf("expected, 3 results, but got %v", len(res))
+		t.FailNow()
+	}
+	var pe PanicError
+	if !errors.As(res[1].Error(), &pe) || pe.Value != "bad record" {
+		t.Logf("expected, PanicError{bad record}, but got %v", res[1].Error())
+		t.Fail()
+	}
+	if !res[0].Ok() || !res[2].Ok() {
+		t.Logf("expected, ok results around the panic, but got %v", res)
+		t.Fail()
+	}
+}
+
+func TestParallelMapRecover(t *testing.T) {
+	var mu sync.Mutex
+	var panics int
+	handler := func(p any) {
+		mu.Lock()
+		defer mu.Unlock()
+		panics++
+	}
+	mapper := func(i int) int {
+		if i%3 == 0 {
+			panic("bad record")
+		}
+		return i
+	}
+
+	res := Collect(ParallelMap(Generate(1, 2, 3, 4, 5, 6, 7), mapper, 3, OpOrdered(), OpRecover(handler)))
+	exp := []int{1, 2, 4, 5, 7}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+
+	res = slicez.Sort(Collect(ParallelMap(Generate(1, 2, 3, 4, 5, 6, 7), mapper, 3, OpRecover(handler))))
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+
+	if panics != 4 {
+		t.Logf("expected, 4, but got %v", panics)
+		t.Fail()
+	}
+}
+
+func TestParallelMapRecoverStop(t *testing.T) {
+	res := Collect(ParallelMap(Generate(1, 2, 3, 4, 5, 6, 7), func(i int) int {
+		if i == 3 {
+			panic("bad record")
+		}
+		return i
+	}, 2, OpOrdered(), OpRecoverStop(nil)))
+
+	// Results finished before the panic may or may not be emitted, but nothing after it
+	exp := []int{1, 2}
+	if len(res) > len(exp) || !slicez.Equal(res, exp[:len(res)]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestParallelMapRecoverStopOpenInput(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		opts := []Option{OpRecoverStop(nil)}
+		if ordered {
+			opts = append(opts, OpOrdered())
+		}
+		in := make(chan int)
+		out := ParallelMap(in, func(i int) int {
+			panic("bad record")
+		}, 3, opts...)
+
+		in <- 1
+		expectClosed(t, out) // even though in is never closed
+	}
+}
+
+func TestCombineLatestRecover(t *testing.T) {
+	combiner := func(a, b int) int {
+		if a == 2 {
+			panic("bad record")
+		}
+		return a * b
+	}
+	combines := map[string]func(a <-chan int, b <-chan int) <-chan int{
+		"CombineLatest": func(a <-chan int, b <-chan int) <-chan int {
+			return CombineLatest(a, b, combiner, OpRecover(nil))
+		},
+		"WithLatestFrom": func(a <-chan int, b <-chan int) <-chan int {
+			return WithLatestFrom(a, b, combiner, OpRecover(nil))
+		},
+	}
+	for name, combine := range combines {
+		a, b := make(chan int), make(chan int)
+		out := combine(a, b)
+		go func() {
+			b <- 10
+			a <- 1
+			a <- 2
+			a <- 3
+			close(a)
+			close(b)
+		}()
+
+		res := Collect(out)
+		exp := []int{10, 30}
+		if !slicez.Equal(res, exp) {
+			t.Logf("%s: expected, %v, but got %v", name, exp, res)
+			t.Fail()
+		}
+	}
+}
+
+func TestJoinByKeyRecover(t *testing.T) {
+	left, right := make(chan int), make(chan int)
+	keyL := func(l int) int {
+		if l == 2 {
+			panic("bad key")
+		}
+		return l
+	}
+	joiner := func(l mon.Option[int], r mon.Option[int]) int {
+		if l.OrEmpty() == 3 {
+			panic("bad join")
+		}
+		return l.OrEmpty()*10 + r.OrEmpty()
+	}
+	out := JoinByKey(left, right, keyL, func(r int) int { return r }, time.Hour, joiner, OpRecover(nil))
+	go func() {
+		for i := 1; i <= 4; i++ {
+			right <- i
+		}
+		for i := 1; i <= 4; i++ {
+			left <- i
+		}
+		close(left)
+		close(right)
+	}()
+
+	res := Collect(out)
+	exp := []int{11, 44}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestKeyHashRecover(t *testing.T) {
+	key := func(s string) string {
+		if s == "b" {
+			panic("bad key")
+		}
+		return s
+	}
+	outs := Distribute(Generate("a", "b", "c"), 1, KeyHash(key), OpRecover(nil))
+
+	res := Collect(outs[0])
+	exp := []string{"a", "c"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestFoldRecover(t *testing.T) {
+	sum := func(acc, i int) int {
+		if i == 2 {
+			panic("bad record")
+		}
+		return acc + i
+	}
+
+	res := Fold(Generate(1, 2, 3), sum, 0, OpRecover(nil))
+	if res != 4 {
+		t.Logf("expected, 4, but got %v", res)
+		t.Fail()
+	}
+
+	res = Fold(GenerateWith[int](OpBuffer(3))(1, 2, 3), sum, 0, OpRecoverStop(nil)) // buffered, so nothing is left blocked
+	if res != 1 {
+		t.Logf("expected, 1, but got %v", res)
+		t.Fail()
+	}
+}
+
+func TestNoRecover(t *testing.T) {
+	defer func() {
+		if p := recover(); p != "bad record" {
+			t.Logf("expected, bad record, but got %v", p)
+			t.Fail()
+		}
+	}()
+	_, _ = call(settings{}, func(i int) int { panic("bad record") }, 1)
+}
@@ -0,0 +1,169 @@
+package chanz
+
+import (
+	"github.com/modfin/henry/mon"
+)
+
+// OpFailFast creates an option that makes error aware stages, such as MapErr, stop at the first error.
+// The error is still passed on, after which cancel is called, if not nil, and the output is closed.
+// Pass the cancel func of the context given to upstream stages using OpContext to tear down the whole pipeline.
+func OpFailFast(cancel func()) Option {
+	return func(s settings) settings {
+		s.failFast = true
+		s.cancel = cancel
+		return s
+	}
+}
+
+// fail is called by error aware stages when they encounter an error, it returns true if the stage should stop
+func (s settings) fail() bool {
+	if !s.failFast {
+		return false
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return true
+}
+
+// MapErr will take a chan, in, and executes mapper and put the resulting value or error, as a mon.Result, on to the return chan.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// or after the first error if OpFailFast is supplied.
+// With OpRecover a panic in mapper is emitted as a PanicError.
+//
+// Example:
+//
+//	input := chanz.Generate("1", "2", "x", "4")
+//	parsed := chanz.MapErr(input, strconv.Atoi)
+//	values, err := chanz.CollectResults(parsed)
+//	// values = []int{1, 2, 4}, err = strconv.ErrSyntax for "x"
+func MapErr[A any, B any](in <-chan A, mapper func(a A) (B, error), options ...Option) <-chan mon.Result[B] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan mon.Result[B], s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
+		for e := range in {
+			s.observe(EventReceived, 0)
+			r := mon.TupleToResult(callErr(s, mapper, e))
+			if !send(s, out, r) {
+				return
+			}
+			if !r.Ok() && s.fail() {
+				return
+			}
+			if _, panicked := r.Error().(PanicError); panicked && s.recoverStop {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MapErrWith returns a configured MapErr function closure.
+// Allows creating reusable mappers with preset options.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	parser := chanz.MapErrWith[string, int](chanz.OpContext(ctx), chanz.OpFailFast(cancel))
+//	parsed := parser(lines, strconv.Atoi)
+func MapErrWith[A any, B any](options ...Option) func(in <-chan A, mapper func(a A) (B, error)) <-chan mon.Result[B] {
+	return func(in <-chan A, mapper func(a A) (B, error)) <-chan mon.Result[B] {
+		return MapErr(in, mapper, options...)
+	}
+}
+
+// SplitResults takes a chan of mon.Result and returns two chans. Values of Ok results are put on the values chan
+// and errors of Err results are put on the errs chan.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// or after the first error if OpFailFast is supplied.
+//
+// Example:
+//
+//	parsed := chanz.MapErr(chanz.Generate("1", "x", "3"), strconv.Atoi)
+//	values, errs := chanz.SplitResults(parsed, chanz.OpBuffer(3))
+//	// values receives 1, 3; errs receives the error for "x"
+func SplitResults[A any](in <-chan mon.Result[A], options ...Option) (values <-chan A, errs <-chan error) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	vals := make(chan A, s.buffer)
+	errors := make(chan error, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(errors)
+			close(vals)
+			discard(s, in)
+		})
+		for r := range in {
+			s.observe(EventReceived, 0)
+			v, err := r.Get()
+			if err != nil {
+				if !send(s, errors, err) {
+					return
+				}
+				if s.fail() {
+					return
+				}
+				continue
+			}
+			if !send(s, vals, v) {
+				return
+			}
+		}
+	}()
+	return vals, errors
+}
+
+// CollectResults will collect the values of all results in a channel into a slice and return it together with the first error.
+// It keeps collecting after an error, unless OpFailFast is supplied, in which case it returns at the first error.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	parsed := chanz.MapErr(chanz.Generate("1", "x", "3"), strconv.Atoi, chanz.OpContext(ctx))
+//	values, err := chanz.CollectResults(parsed, chanz.OpFailFast(cancel))
+//	// values = []int{1}, err is the error for "x"
+func CollectResults[A any](in <-chan mon.Result[A], options ...Option) ([]A, error) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	var out []A
+	var first error
+	for r := range in {
+		v, err := r.Get()
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			if s.fail() {
+				go discard(s, in)
+				return out, first
+			}
+		} else {
+			out = append(out, v)
+		}
+		select {
+		case <-s.stop():
+			return out, first
+		default:
+		}
+	}
+	return out, first
+}
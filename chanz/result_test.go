@@ -0,0 +1,114 @@
+package chanz
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestMapErr(t *testing.T) {
+	res := Collect(MapErr(Generate("1", "2", "x", "4"), strconv.Atoi))
+
+	if len(res) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(res))
+	}
+	for i, exp := range []int{1, 2, 0, 4} {
+		v, err := res[i].Get()
+		if (i == 2) != (err != nil) {
+			t.Errorf("unexpected error state for result %d, %v", i, err)
+		}
+		if v != exp {
+			t.Errorf("expected %d, got %d", exp, v)
+		}
+	}
+}
+
+func TestMapErrFailFast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	upstream := Generator(func(yield func(string)) {
+		for i := 0; ctx.Err() == nil; i++ {
+			if i == 3 {
+				yield("x")
+				continue
+			}
+			yield(strconv.Itoa(i))
+		}
+	}, OpContext(ctx))
+
+	res := Collect(MapErr(upstream, strconv.Atoi, OpFailFast(cancel)))
+	if len(res) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(res))
+	}
+	if res[3].Ok() {
+		t.Error("expected last result to be the error")
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("expected upstream to be cancelled")
+	}
+}
+
+func TestSplitResults(t *testing.T) {
+	values, errs := SplitResults(MapErr(Generate("1", "x", "3", "y"), strconv.Atoi))
+
+	var resErrs []error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resErrs = Collect(errs)
+	}()
+	resValues := Collect(values)
+	wg.Wait()
+
+	exp := []int{1, 3}
+	if !slicez.Equal(exp, resValues) {
+		t.Logf("expected, %v, but got %v", exp, resValues)
+		t.Fail()
+	}
+	if len(resErrs) != 2 {
+		t.Errorf("expected 2 errors, got %v", resErrs)
+	}
+}
+
+func TestCollectResults(t *testing.T) {
+	values, err := CollectResults(MapErr(Generate("1", "x", "3", "y"), strconv.Atoi))
+	exp := []int{1, 3}
+	if !slicez.Equal(exp, values) {
+		t.Logf("expected, %v, but got %v", exp, values)
+		t.Fail()
+	}
+	if err == nil || err.Error() != `strconv.Atoi: parsing "x": invalid syntax` {
+		t.Errorf("expected first error, got %v", err)
+	}
+
+	var cancelled bool
+	values, err = CollectResults(MapErr(Generate("1", "x", "3"), strconv.Atoi), OpFailFast(func() { cancelled = true }))
+	exp = []int{1}
+	if !slicez.Equal(exp, values) {
+		t.Logf("expected, %v, but got %v", exp, values)
+		t.Fail()
+	}
+	if err == nil || !cancelled {
+		t.Errorf("expected fail fast to return error and cancel, got %v, %v", err, cancelled)
+	}
+}
+
+func TestMapErrWith(t *testing.T) {
+	parser := MapErrWith[string, int](OpBuffer(2))
+	values, err := CollectResults(parser(Generate("1", "2"), strconv.Atoi))
+
+	exp := []int{1, 2}
+	if !slicez.Equal(exp, values) || err != nil {
+		t.Logf("expected, %v, but got %v, %v", exp, values, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,74 @@
+package chanz
+
+import (
+	"github.com/modfin/henry/mon"
+)
+
+// MapRetry will take a chan, in, and executes fn, retrying it according to policy using mon.Retry, and put the
+// resulting value or the error of the last attempt, as a mon.Result, on to the return chan.
+// If policy.Context is nil the retries are stopped by the context supplied with OpContext or the "done" channel.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// or after the first error if OpFailFast is supplied.
+//
+// Example:
+//
+//	policy := mon.RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second, Jitter: 0.2}
+//	responses := chanz.MapRetry(requests, send, policy, chanz.OpContext(ctx))
+//	values, errs := chanz.SplitResults(responses)
+func MapRetry[A any, B any](in <-chan A, fn func(a A) (B, error), policy mon.RetryPolicy, options ...Option) <-chan mon.Result[B] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	ctx, cancel := s.stageContext()
+	if policy.Context == nil {
+		policy.Context = ctx
+	}
+	retry := func(a A) (B, error) {
+		return mon.Retry(policy, func() (B, error) {
+			return fn(a)
+		}).Get()
+	}
+
+	out := make(chan mon.Result[B], s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
+		defer cancel()
+		for e := range in {
+			s.observe(EventReceived, 0)
+			r := mon.TupleToResult(callErr(s, retry, e))
+			if ctx.Err() != nil {
+				return
+			}
+			if !send(s, out, r) {
+				return
+			}
+			if !r.Ok() && s.fail() {
+				return
+			}
+			if _, panicked := r.Error().(PanicError); panicked && s.recoverStop {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MapRetryWith returns a configured MapRetry function closure.
+// Allows creating reusable retrying mappers with preset options.
+//
+// Example:
+//
+//	retrier := chanz.MapRetryWith[Request, Response](chanz.OpContext(ctx), chanz.OpBuffer(10))
+//	responses := retrier(requests, send, policy)
+func MapRetryWith[A any, B any](options ...Option) func(in <-chan A, fn func(a A) (B, error), policy mon.RetryPolicy) <-chan mon.Result[B] {
+	return func(in <-chan A, fn func(a A) (B, error), policy mon.RetryPolicy) <-chan mon.Result[B] {
+		return MapRetry(in, fn, policy, options...)
+	}
+}
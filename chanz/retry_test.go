@@ -0,0 +1,98 @@
+package chanz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestMapRetry(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	attempts := map[int]int{}
+	var delays []time.Duration
+	policy := mon.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	res := Collect(MapRetry(Generate(1, 2, 3), func(i int) (int, error) {
+		attempts[i]++
+		if attempts[i] < i { // 1 succeeds at once, 2 on the second attempt and 3 on the third
+			return 0, errFlaky
+		}
+		return i * 10, nil
+	}, policy))
+
+	values := slicez.Map(res, func(r mon.Result[int]) int { return r.OrEmpty() })
+	exp := []int{10, 20, 30}
+	if !slicez.Equal(values, exp) {
+		t.Logf("expected, %v, but got %v", exp, values)
+		t.Fail()
+	}
+	expDelays := []time.Duration{time.Second, time.Second, 2 * time.Second}
+	if !slicez.Equal(delays, expDelays) {
+		t.Logf("expected, %v, but got %v", expDelays, delays)
+		t.Fail()
+	}
+}
+
+func TestMapRetryGivesUp(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	policy := mon.RetryPolicy{
+		MaxAttempts: 2,
+		Sleep:       func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	res := Collect(MapRetry(Generate(1, 2), func(i int) (int, error) {
+		if i == 1 {
+			return 0, errFlaky
+		}
+		return i, nil
+	}, policy))
+
+	if len(res) != 2 || res[0].Error() != errFlaky || !res[1].Ok() {
+		t.Logf("expected, [Err(flaky) Ok(2)], but got %v", res)
+		t.Fail()
+	}
+}
+
+func TestMapRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sleeping := make(chan struct{})
+	stopped := make(chan error, 1)
+	policy := mon.RetryPolicy{
+		MaxAttempts: 100,
+		BaseDelay:   time.Hour,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			close(sleeping)
+			<-ctx.Done()
+			stopped <- ctx.Err()
+			return ctx.Err()
+		},
+	}
+	res := MapRetry(Generate(1), func(i int) (int, error) {
+		return 0, errors.New("down")
+	}, policy, OpContext(ctx))
+
+	<-sleeping
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != context.Canceled {
+			t.Logf("expected, %v, but got %v", context.Canceled, err)
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected, the backoff to be cancelled by the stage context")
+	}
+	expectClosed(t, res)
+}
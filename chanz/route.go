@@ -0,0 +1,122 @@
+package chanz
+
+// Route takes a chan and returns n chans, and a chan for unmatched elements. Every element is put on the chan with the
+// index returned by router, or on unmatched if the index is not within [0, n).
+// Like FanOut, a single goroutine does the routing, so an output that is not read will block all the others,
+// this also goes for unmatched, drain it with DropAll if it is not needed.
+// All chans are closed when "in" is closed.
+// The return chans have a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	outs, unmatched := chanz.Route(orders, func(o Order) int { return o.Priority }, 3)
+//	high, medium, low := outs[0], outs[1], outs[2]
+//	chanz.DropAll(unmatched, true)
+func Route[A any](in <-chan A, router func(a A) int, n int, options ...Option) (outs []<-chan A, unmatched <-chan A) {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	routes := make([]chan A, n)
+	for i := range routes {
+		routes[i] = make(chan A, s.buffer)
+	}
+	rest := make(chan A, s.buffer)
+
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			for _, o := range routes {
+				close(o)
+			}
+			close(rest)
+			discard(s, in)
+		})
+
+		for e := range in {
+			s.observe(EventReceived, 0)
+			i, ok := call(s, router, e)
+			if !ok {
+				if s.recoverStop {
+					return
+				}
+				continue
+			}
+			out := rest
+			if 0 <= i && i < n {
+				out = routes[i]
+			}
+			if !send(s, out, e) {
+				return
+			}
+		}
+	}()
+	return Readers(routes...), rest
+}
+
+// RouteWith returns a configured Route function closure.
+// Allows creating reusable routers with preset options.
+//
+// Example:
+//
+//	router := chanz.RouteWith[Order](chanz.OpBuffer(10), chanz.OpContext(ctx))
+//	outs, unmatched := router(orders, byPriority, 3)
+func RouteWith[A any](options ...Option) func(in <-chan A, router func(a A) int, n int) (outs []<-chan A, unmatched <-chan A) {
+	return func(in <-chan A, router func(a A) int, n int) (outs []<-chan A, unmatched <-chan A) {
+		return Route(in, router, n, options...)
+	}
+}
+
+// RouteBy takes a chan and returns a chan for every key in keys, and a chan for unmatched elements. Every element is
+// put on the chan of the key returned by key, or on unmatched if the key is not in keys.
+// It follows the same rules as Route, an output that is not read will block all the others.
+// The return chans have a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	outs, deadLetters := chanz.RouteBy(events, func(e Event) string { return e.Type },
+//	    []string{"created", "updated", "deleted"})
+//	go handleCreated(outs["created"])
+//	go handleUpdated(outs["updated"])
+//	go handleDeleted(outs["deleted"])
+//	go logUnknown(deadLetters)
+func RouteBy[A any, K comparable](in <-chan A, key func(a A) K, keys []K, options ...Option) (outs map[K]<-chan A, unmatched <-chan A) {
+	index := make(map[K]int, len(keys))
+	for _, k := range keys {
+		if _, ok := index[k]; !ok {
+			index[k] = len(index)
+		}
+	}
+
+	routes, unmatched := Route(in, func(a A) int {
+		if i, ok := index[key(a)]; ok {
+			return i
+		}
+		return -1
+	}, len(index), options...)
+
+	outs = make(map[K]<-chan A, len(index))
+	for k, i := range index {
+		outs[k] = routes[i]
+	}
+	return outs, unmatched
+}
+
+// RouteByWith returns a configured RouteBy function closure.
+// Allows creating reusable routers with preset options.
+//
+// Example:
+//
+//	router := chanz.RouteByWith[Event, string](chanz.OpBuffer(10))
+//	outs, deadLetters := router(events, eventType, []string{"created", "deleted"})
+func RouteByWith[A any, K comparable](options ...Option) func(in <-chan A, key func(a A) K, keys []K) (outs map[K]<-chan A, unmatched <-chan A) {
+	return func(in <-chan A, key func(a A) K, keys []K) (outs map[K]<-chan A, unmatched <-chan A) {
+		return RouteBy(in, key, keys, options...)
+	}
+}
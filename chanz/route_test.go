@@ -0,0 +1,103 @@
+package chanz
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modfin/henry/slicez"
+)
+
+// collectRoutes collects all outputs, and unmatched, concurrently
+func collectRoutes[A any](outs []<-chan A, unmatched <-chan A) ([][]A, []A) {
+	var rest []A
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rest = Collect(unmatched)
+	}()
+	res := collectAll(outs)
+	wg.Wait()
+	return res, rest
+}
+
+func TestRoute(t *testing.T) {
+	outs, unmatched := Route(Generate(1, 2, 3, 4, 5, 6, 7, 8, 9), func(i int) int { return i % 4 }, 3)
+	res, rest := collectRoutes(outs, unmatched)
+
+	exp := [][]int{{4, 8}, {1, 5, 9}, {2, 6}}
+	if !slicez.EqualBy(res, exp, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	expRest := []int{3, 7}
+	if !slicez.Equal(rest, expRest) {
+		t.Logf("expected, %v, but got %v", expRest, rest)
+		t.Fail()
+	}
+}
+
+func TestRouteNegative(t *testing.T) {
+	outs, unmatched := Route(Generate(-1, 0, 1), func(i int) int { return i }, 1)
+	res, rest := collectRoutes(outs, unmatched)
+
+	if !slicez.Equal(res[0], []int{0}) || !slicez.Equal(rest, []int{-1, 1}) {
+		t.Logf("expected, [0] and [-1 1], but got %v and %v", res, rest)
+		t.Fail()
+	}
+}
+
+func TestRouteContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+	outs, unmatched := Route(in, func(i int) int { return 0 }, 2, OpContext(ctx))
+
+	expectNext(t, outs[0], 1)
+	cancel()
+	expectClosed(t, outs[1])
+	expectClosed(t, unmatched)
+}
+
+func TestRouteBy(t *testing.T) {
+	type event struct {
+		kind string
+		id   int
+	}
+	events := Generate(
+		event{"created", 1}, event{"updated", 1}, event{"created", 2},
+		event{"archived", 1}, event{"deleted", 2},
+	)
+	outs, unmatched := RouteBy(events, func(e event) string { return e.kind }, []string{"created", "updated", "deleted"})
+	if len(outs) != 3 {
+		t.Fatalf("expected, 3 outputs, but got %v", len(outs))
+	}
+
+	ids := func(es []event) []int { return slicez.Map(es, func(e event) int { return e.id }) }
+	res, rest := collectRoutes([]<-chan event{outs["created"], outs["updated"], outs["deleted"]}, unmatched)
+
+	exp := [][]int{{1, 2}, {1}, {2}}
+	got := slicez.Map(res, ids)
+	if !slicez.EqualBy(got, exp, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, got)
+		t.Fail()
+	}
+	if len(rest) != 1 || rest[0].kind != "archived" {
+		t.Logf("expected, [archived], but got %v", rest)
+		t.Fail()
+	}
+}
+
+func TestRouteByWith(t *testing.T) {
+	router := RouteByWith[int, bool](OpBuffer(10))
+	outs, unmatched := router(Generate(1, 2, 3, 4), func(i int) bool { return i%2 == 0 }, []bool{true})
+
+	DropAll(unmatched, true)
+	res := Collect(outs[true])
+	if !slicez.Equal(res, []int{2, 4}) {
+		t.Logf("expected, [2 4], but got %v", res)
+		t.Fail()
+	}
+}
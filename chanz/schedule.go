@@ -0,0 +1,325 @@
+package chanz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval returns a chan on which the time is put every d, like a time.Ticker. Ticks are dropped, rather than
+// queued, for a consumer that falls behind. Interval panics if d <= 0.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once the "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// without either it never stops.
+//
+// Example:
+//
+//	ticks := chanz.Interval(time.Minute, chanz.OpContext(ctx))
+//	snapshots := chanz.Map(ticks, func(t time.Time) Snapshot { return portfolio.Snapshot(t) })
+func Interval(d time.Duration, options ...Option) <-chan time.Time {
+	if d <= 0 {
+		panic("chanz: non-positive interval for Interval")
+	}
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	out := make(chan time.Time, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		ticker := s.getClock().NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case t := <-ticker.C():
+				if !send(s, out, t) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// IntervalWith returns a configured Interval function closure.
+// Allows creating reusable intervals with preset options.
+//
+// Example:
+//
+//	every := chanz.IntervalWith(chanz.OpContext(ctx))
+//	ticks := every(time.Minute)
+func IntervalWith(options ...Option) func(d time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		return Interval(d, options...)
+	}
+}
+
+// After returns a chan on which the time is put once, after d, like time.After, and which is then closed.
+// A d <= 0 emits right away.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once the "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	start := chanz.After(5*time.Second, chanz.OpContext(ctx))
+//	warmup := chanz.Map(start, func(time.Time) Cache { return loadCache() })
+func After(d time.Duration, options ...Option) <-chan time.Time {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	out := make(chan time.Time, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		timer := s.getClock().NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-s.done:
+		case t := <-timer.C():
+			send(s, out, t)
+		}
+	}()
+	return out
+}
+
+// AfterWith returns a configured After function closure.
+// Allows creating reusable timers with preset options.
+//
+// Example:
+//
+//	after := chanz.AfterWith(chanz.OpContext(ctx))
+//	deadline := after(time.Minute)
+func AfterWith(options ...Option) func(d time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		return After(d, options...)
+	}
+}
+
+// Schedule returns a chan on which the scheduled time is put at every time matching spec, a cron expression, see
+// ParseCron. It returns an error if spec can not be parsed.
+// Times are matched in the location of the time returned by the clock, which for the wall clock is time.Local.
+// A time that was missed by a consumer that fell behind is skipped, rather than queued.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once the "done" channel is closed or the context.Done is closed, which is supplied in Option,
+// or when spec matches no more times.
+//
+// Example:
+//
+//	// Rebalance at 09:00 on weekdays
+//	runs, err := chanz.Schedule("0 9 * * 1-5", chanz.OpContext(ctx))
+//	if err != nil {
+//	    return err
+//	}
+//	for t := range runs {
+//	    rebalance(t)
+//	}
+func Schedule(spec string, options ...Option) (<-chan time.Time, error) {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	s = s.source()
+
+	out := make(chan time.Time, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		clock := s.getClock()
+		var last time.Time
+		for {
+			from := clock.Now()
+			if from.Before(last) {
+				from = last
+			}
+			next := cron.Next(from)
+			if next.IsZero() {
+				return
+			}
+
+			timer := clock.NewTimer(next.Sub(clock.Now()))
+			select {
+			case <-s.done:
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+			if !send(s, out, next) {
+				return
+			}
+			last = next
+		}
+	}()
+	return out, nil
+}
+
+// ScheduleWith returns a configured Schedule function closure.
+// Allows creating reusable schedules with preset options.
+//
+// Example:
+//
+//	schedule := chanz.ScheduleWith(chanz.OpContext(ctx))
+//	hourly, err := schedule("0 * * * *")
+func ScheduleWith(options ...Option) func(spec string) (<-chan time.Time, error) {
+	return func(spec string) (<-chan time.Time, error) {
+		return Schedule(spec, options...)
+	}
+}
+
+// Cron is a parsed cron expression, see ParseCron.
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // bit i is set if value i matches
+	domAny, dowAny                bool   // the field starts with "*", which changes how dom and dow are combined
+}
+
+// cronFields are the bounds of the fields of a cron expression, in order
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a cron expression of five space separated fields, minute, hour, day of month, month and day of
+// week, where Sunday is 0 or 7. A field is a comma separated list of "*", a value, or a range "a-b", where "*" and
+// ranges may be followed by a step, "/n".
+// As in cron, a time matches if both day of month and day of week match, unless neither of them starts with "*",
+// then either of them matching is enough.
+//
+// Example:
+//
+//	chanz.ParseCron("*/15 * * * *")   // every quarter of an hour
+//	chanz.ParseCron("30 8 1 * *")     // 08:30 on the first of every month
+//	chanz.ParseCron("0 9-17 * * 1-5") // every hour, on the hour, from 09:00 to 17:00 on weekdays
+func ParseCron(spec string) (Cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return Cron{}, fmt.Errorf("chanz: cron %q: expected %d fields, got %d", spec, len(cronFields), len(fields))
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return Cron{}, fmt.Errorf("chanz: cron %q: %s: %w", spec, cronFields[i].name, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 { // Sunday as 7
+		bits[4] |= 1
+	}
+	return Cron{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: strings.HasPrefix(fields[2], "*"),
+		dowAny: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the values matched by field as a bit set
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			expr, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case expr == "*":
+		case strings.Contains(expr, "-"):
+			i := strings.Index(expr, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(expr[:i])
+			hi, err2 = strconv.Atoi(expr[i+1:])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", expr)
+			}
+		default:
+			n, err := strconv.Atoi(expr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", expr)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = max // "a/n" means from a to max every n
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range [%d, %d]", expr, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t that matches the expression, in the location of t, or the zero time if
+// there is none within five years, e.g. for "0 0 30 2 *".
+func (c Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay returns true if the day of t matches day of month and day of week
+func (c Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,170 @@
+package chanz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+)
+
+func TestInterval(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := chanz.Interval(time.Second, chanz.OpClock(clock), chanz.OpContext(ctx))
+
+	start := clock.Now()
+	clock.BlockUntilArmed(t, 1)
+	chanztest.ExpectNothing(t, ticks, chanztest.ShortWait)
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, ticks, time.Second, start.Add(time.Second))
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, ticks, time.Second, start.Add(2*time.Second))
+
+	cancel()
+	chanztest.ExpectClosed(t, ticks, time.Second)
+}
+
+func TestIntervalPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Log("expected, panic for a non-positive interval")
+			t.Fail()
+		}
+	}()
+	chanz.Interval(0)
+}
+
+func TestAfter(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	timer := chanz.After(time.Minute, chanz.OpClock(clock))
+
+	start := clock.Now()
+	clock.BlockUntilArmed(t, 1)
+	chanztest.ExpectNothing(t, timer, chanztest.ShortWait)
+	clock.Advance(time.Minute)
+	chanztest.ExpectSequence(t, timer, time.Second, start.Add(time.Minute))
+	chanztest.ExpectClosed(t, timer, time.Second)
+}
+
+func TestAfterDone(t *testing.T) {
+	done := make(chan struct{})
+	timer := chanz.After(time.Minute, chanz.OpClock(chanztest.NewFakeClock(start)), chanz.OpDone(done))
+	close(done)
+	chanztest.ExpectClosed(t, timer, time.Second)
+}
+
+func TestSchedule(t *testing.T) {
+	clock := chanztest.NewFakeClock(start) // 2022-01-01 00:00, a Saturday
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs, err := chanz.Schedule("*/15 * * * *", chanz.OpClock(clock), chanz.OpContext(ctx))
+	if err != nil {
+		t.Fatalf("expected, no error, but got %v", err)
+	}
+
+	start := clock.Now()
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(14 * time.Minute)
+	chanztest.ExpectNothing(t, runs, chanztest.ShortWait)
+	clock.Advance(time.Minute)
+	chanztest.ExpectSequence(t, runs, time.Second, start.Add(15*time.Minute))
+
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(15 * time.Minute)
+	chanztest.ExpectSequence(t, runs, time.Second, start.Add(30*time.Minute))
+
+	cancel()
+	chanztest.ExpectClosed(t, runs, time.Second)
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	if _, err := chanz.Schedule("* * *"); err == nil {
+		t.Log("expected, error for an invalid spec")
+		t.Fail()
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+	} {
+		if _, err := chanz.ParseCron(spec); err == nil {
+			t.Logf("expected, error for %q", spec)
+			t.Fail()
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		spec string
+		from string
+		exp  string
+	}{
+		{"* * * * *", "2022-01-01 00:00", "2022-01-01 00:01"},
+		{"*/15 * * * *", "2022-01-01 00:07", "2022-01-01 00:15"},
+		{"30 8 * * *", "2022-01-01 08:30", "2022-01-02 08:30"},
+		{"0 9 * * 1-5", "2022-01-01 12:00", "2022-01-03 09:00"},    // Saturday to Monday
+		{"0 9-17/4 * * *", "2022-01-01 09:00", "2022-01-01 13:00"}, // stepped range
+		{"5,10 0 * * *", "2022-01-01 00:06", "2022-01-01 00:10"},   // list
+		{"0 0 1 */3 *", "2022-01-15 00:00", "2022-04-01 00:00"},    // quarterly
+		{"0 0 29 2 *", "2022-01-01 00:00", "2024-02-29 00:00"},     // leap day
+		{"0 0 * * 7", "2022-01-01 00:00", "2022-01-02 00:00"},      // Sunday as 7
+		{"0 0 13 * 5", "2022-01-01 00:00", "2022-01-07 00:00"},     // dom or dow, first Friday
+		{"0 0 */10 * 5", "2022-01-01 00:00", "2022-01-21 00:00"},   // dom and dow, as dom starts with *
+		{"0 0 */10 * *", "2022-01-01 00:00", "2022-01-11 00:00"},   // dom only
+		{"0 0 30 2 *", "2022-01-01 00:00", ""},                     // never
+		{"59 23 31 12 *", "2022-12-31 23:58", "2022-12-31 23:59"},  // last minute of the year
+		{"0 0 1 1 *", "2022-12-31 23:59", "2023-01-01 00:00"},      // year wrap
+		{"15 * * * *", "2022-01-01 10:15:30", "2022-01-01 11:15"},  // seconds are truncated
+	}
+	for _, test := range tests {
+		c, err := chanz.ParseCron(test.spec)
+		if err != nil {
+			t.Fatalf("expected, no error for %q, but got %v", test.spec, err)
+		}
+		from, err := time.Parse("2006-01-02 15:04:05", test.from)
+		if err != nil {
+			from = at(test.from)
+		}
+		var exp time.Time
+		if test.exp != "" {
+			exp = at(test.exp)
+		}
+		if res := c.Next(from); !res.Equal(exp) {
+			t.Logf("%q from %s: expected, %v, but got %v", test.spec, test.from, exp, res)
+			t.Fail()
+		}
+	}
+}
+
+func TestCronNextLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	c, _ := chanz.ParseCron("0 9 * * *")
+	from := time.Date(2022, 1, 1, 10, 0, 0, 0, loc)
+	exp := time.Date(2022, 1, 2, 9, 0, 0, 0, loc)
+	if res := c.Next(from); !res.Equal(exp) || res.Location() != loc {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
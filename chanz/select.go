@@ -0,0 +1,264 @@
+package chanz
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Tagged is an element received by Select or a Multiplexer, together with the source it was received from.
+type Tagged[A any] struct {
+	Index int  // Index of the source chan in Select, or the id returned by Multiplexer.Add
+	Value A    // The element, the zero value if Ok is false
+	Ok    bool // False when the source has been closed
+}
+
+// Select takes a slice of chans and returns a chan on which every element received from any of them is put, tagged
+// with the index of the chan it was received from. When a chan is closed a Tagged with Ok false is put on the
+// returning chan, once. Unlike FanIn, a single goroutine reads from all chans, using reflect.Select, which is
+// suitable for a large or runtime-sized set of chans. Nil chans are ignored.
+// The return chan has a buffer of 0, use SelectWith to supply options.
+// It will stop once all chans are closed.
+//
+// Example:
+//
+//	feeds := []<-chan Quote{nasdaq, nyse, lse}
+//	for t := range chanz.Select(feeds...) {
+//	    if !t.Ok {
+//	        log.Printf("feed %d closed", t.Index)
+//	        continue
+//	    }
+//	    handle(t.Index, t.Value)
+//	}
+func Select[A any](chans ...<-chan A) <-chan Tagged[A] {
+	return SelectWith[A]()(chans...)
+}
+
+// SelectWith returns a configured Select function closure.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once all chans are closed, or the "done" channel is closed or the context.Done is closed, which is
+// supplied in Option
+//
+// Example:
+//
+//	selector := chanz.SelectWith[Quote](chanz.OpContext(ctx), chanz.OpBuffer(100))
+//	quotes := selector(feeds...)
+func SelectWith[A any](options ...Option) func(chans ...<-chan A) <-chan Tagged[A] {
+	return func(chans ...<-chan A) <-chan Tagged[A] {
+		var s settings
+		for _, o := range options {
+			s = o(s)
+		}
+
+		// The first case stops the stage, the remaining cases are the chans, in order
+		cases := make([]reflect.SelectCase, len(chans)+1)
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.stop())}
+		var open int
+		for i, c := range chans {
+			cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv}
+			if c != nil {
+				cases[i+1].Chan = reflect.ValueOf(c)
+				open++
+			}
+		}
+
+		out := make(chan Tagged[A], s.buffer)
+		s.started()
+		go func() {
+			defer s.finished(func() { close(out) })
+			for open > 0 {
+				chosen, recv, ok := reflect.Select(cases)
+				if chosen == 0 {
+					return
+				}
+				t := Tagged[A]{Index: chosen - 1, Ok: ok}
+				if ok {
+					s.observe(EventReceived, 0)
+					t.Value, _ = recv.Interface().(A)
+				} else {
+					cases[chosen].Chan = reflect.Value{} // the zero Value makes reflect.Select ignore the case
+					open--
+				}
+				if !send(s, out, t) {
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Multiplexer reads from a set of chans, that can be changed at runtime, and puts every element received on a single
+// chan, tagged with the id of the chan it was received from. All chans are read by a single goroutine.
+//
+// Example:
+//
+//	mux := chanz.NewMultiplexer[Quote](chanz.OpBuffer(100))
+//	defer mux.Close()
+//
+//	ids := map[int]string{}
+//	ids[mux.Add(subscribe("AAPL"))] = "AAPL"
+//	ids[mux.Add(subscribe("MSFT"))] = "MSFT"
+//
+//	for t := range mux.Out() {
+//	    handle(ids[t.Index], t.Value)
+//	}
+type Multiplexer[A any] struct {
+	s settings
+
+	mu      sync.Mutex
+	sources map[int]<-chan A
+	nextID  int
+
+	changed   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	out       chan Tagged[A]
+}
+
+// NewMultiplexer creates a Multiplexer without any sources and starts reading.
+// The chan returned by Out has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once Close is called, or the "done" channel is closed or the context.Done is closed, which is
+// supplied in Option
+func NewMultiplexer[A any](options ...Option) *Multiplexer[A] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	closed := make(chan struct{})
+	s = s.withAbort(closed)
+	m := &Multiplexer[A]{
+		s:       s,
+		sources: map[int]<-chan A{},
+		changed: make(chan struct{}, 1),
+		closed:  closed,
+		out:     make(chan Tagged[A], s.buffer),
+	}
+	m.s.started()
+	go m.run()
+	return m
+}
+
+// Add starts reading from c and returns the id its elements are tagged with.
+// When c is closed a Tagged with Ok false is emitted and c is removed.
+func (m *Multiplexer[A]) Add(c <-chan A) int {
+	m.mu.Lock()
+	id := m.nextID
+	m.nextID++
+	m.sources[id] = c
+	m.mu.Unlock()
+	m.notify()
+	return id
+}
+
+// Remove stops reading from the chan with id, it returns false if there is no such chan.
+// An element that is being received from the chan as Remove is called may still be emitted after Remove returns,
+// but no Tagged with Ok false is emitted for a removed chan.
+func (m *Multiplexer[A]) Remove(id int) bool {
+	m.mu.Lock()
+	_, ok := m.sources[id]
+	delete(m.sources, id)
+	m.mu.Unlock()
+	if ok {
+		m.notify()
+	}
+	return ok
+}
+
+// Len returns the number of chans being read from.
+func (m *Multiplexer[A]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sources)
+}
+
+// Out returns the chan that tagged elements are put on. It is closed after Close is called.
+func (m *Multiplexer[A]) Out() <-chan Tagged[A] {
+	return m.out
+}
+
+// Close stops reading from all chans and closes the chan returned by Out. It is safe to call Close more than once.
+func (m *Multiplexer[A]) Close() {
+	m.closeOnce.Do(func() {
+		close(m.closed)
+	})
+}
+
+// notify wakes up the reading goroutine so that it picks up changes to the sources
+func (m *Multiplexer[A]) notify() {
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run reads from the sources until the multiplexer is closed.
+// The first cases are done, closed and changed, the remaining cases are the sources in ids order.
+func (m *Multiplexer[A]) run() {
+	s := m.s
+	defer s.finished(func() { close(m.out) })
+
+	var ids []int
+	var chans []<-chan A
+	var cases []reflect.SelectCase
+	rebuild := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		ids = ids[:0]
+		chans = chans[:0]
+		cases = append(cases[:0],
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.stop())},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.closed)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.changed)},
+		)
+		for id, c := range m.sources {
+			ids = append(ids, id)
+			chans = append(chans, c)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c)})
+		}
+	}
+	// current returns true if the source with id still is c, i.e. it has not been removed since the last rebuild
+	current := func(id int, c <-chan A) bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		src, ok := m.sources[id]
+		return ok && src == c
+	}
+
+	rebuild()
+	for {
+		chosen, recv, ok := reflect.Select(cases)
+		switch chosen {
+		case 0, 1:
+			return
+		case 2:
+			rebuild()
+			continue
+		}
+
+		id := ids[chosen-3]
+		t := Tagged[A]{Index: id, Ok: ok}
+		switch {
+		case !current(id, chans[chosen-3]):
+			// Removed since the last rebuild, an element already received is still passed on
+			rebuild()
+			if !ok {
+				continue
+			}
+			s.observe(EventReceived, 0)
+			t.Value, _ = recv.Interface().(A)
+		case ok:
+			s.observe(EventReceived, 0)
+			t.Value, _ = recv.Interface().(A)
+		default:
+			m.mu.Lock()
+			delete(m.sources, id)
+			m.mu.Unlock()
+			rebuild()
+		}
+
+		if !send(s, m.out, t) {
+			return
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package chanz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestSelect(t *testing.T) {
+	a := Generate(1, 2, 3)
+	b := Generate(10, 20)
+	var none <-chan int
+
+	var values [2][]int
+	var closed []int
+	for tagged := range Select(a, none, b) {
+		if !tagged.Ok {
+			closed = append(closed, tagged.Index)
+			continue
+		}
+		i := tagged.Index / 2 // a is index 0 and b index 2
+		values[i] = append(values[i], tagged.Value)
+	}
+
+	if !slicez.Equal(values[0], []int{1, 2, 3}) || !slicez.Equal(values[1], []int{10, 20}) {
+		t.Logf("expected, [[1 2 3] [10 20]], but got %v", values)
+		t.Fail()
+	}
+	if !slicez.Equal(slicez.Sort(closed), []int{0, 2}) {
+		t.Logf("expected, [0 2], but got %v", closed)
+		t.Fail()
+	}
+}
+
+func TestSelectWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	res := SelectWith[int](OpContext(ctx))(make(chan int), make(chan int))
+	cancel()
+	expectClosed(t, res)
+}
+
+func TestSelectNil(t *testing.T) {
+	expectClosed(t, Select[int]())
+}
+
+func TestMultiplexer(t *testing.T) {
+	mux := NewMultiplexer[string]()
+	defer mux.Close()
+
+	aapl := make(chan string)
+	msft := make(chan string)
+	idA := mux.Add(aapl)
+	idM := mux.Add(msft)
+	if mux.Len() != 2 {
+		t.Logf("expected, 2, but got %v", mux.Len())
+		t.Fail()
+	}
+
+	aapl <- "a1"
+	expectNext(t, mux.Out(), Tagged[string]{Index: idA, Value: "a1", Ok: true})
+	msft <- "m1"
+	expectNext(t, mux.Out(), Tagged[string]{Index: idM, Value: "m1", Ok: true})
+
+	// A removed source is no longer read, and its closing is not reported
+	if !mux.Remove(idA) || mux.Remove(idA) {
+		t.Logf("expected, Remove to return true only once")
+		t.Fail()
+	}
+	close(aapl)
+
+	// Sources can be added after the fact, and closing one is reported
+	goog := make(chan string)
+	idG := mux.Add(goog)
+	goog <- "g1"
+	expectNext(t, mux.Out(), Tagged[string]{Index: idG, Value: "g1", Ok: true})
+	close(goog)
+	expectNext(t, mux.Out(), Tagged[string]{Index: idG})
+	if mux.Len() != 1 {
+		t.Logf("expected, 1, but got %v", mux.Len())
+		t.Fail()
+	}
+
+	mux.Close()
+	expectClosed(t, mux.Out())
+	mux.Close()
+}
+
+func TestMultiplexerCloseWhileBlocked(t *testing.T) {
+	mux := NewMultiplexer[int]()
+	mux.Add(Generate(1, 2, 3))
+	time.Sleep(10 * time.Millisecond) // let it block on emitting the first element
+	mux.Close()
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-mux.Out():
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected, Out to be closed")
+		}
+	}
+}
+
+func TestMultiplexerContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mux := NewMultiplexer[int](OpContext(ctx))
+	mux.Add(make(chan int))
+	cancel()
+	expectClosed(t, mux.Out())
+}
@@ -0,0 +1,175 @@
+package chanz
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ShutdownMode is what stages do once the "done" channel or the context.Done, supplied in Option, is closed.
+type ShutdownMode int
+
+const (
+	// ShutdownAbort stops every stage right away, dropping elements that are in flight or buffered. It is the default.
+	ShutdownAbort ShutdownMode = iota
+	// ShutdownDrain only stops sources, such as Generator, Interval or Lines, that produce elements rather than
+	// receive them from a chan. Every other stage keeps going until its input is closed, flushing buffered and
+	// in-flight elements downstream before closing its output, so the shutdown travels through the pipeline
+	// from its sources to its end and nothing already taken in is lost.
+	// A stage that stops before its input is closed, such as Take once it has taken enough, closes its output and
+	// discards the rest of its input, so that the stages upstream can still drain.
+	// A stage reading from a chan that is never closed never stops, close the chans feeding a pipeline on shutdown.
+	ShutdownDrain
+)
+
+// OpShutdown creates an option that sets what stages do once they are told to stop, default is ShutdownAbort.
+//
+// Example:
+//
+//	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer cancel()
+//	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain)}
+//	messages, _ := chanz.Lines(conn, opts...)
+//	written := chanz.MapErr(messages, store, opts...) // every message read before SIGTERM is stored
+func OpShutdown(mode ShutdownMode) Option {
+	return func(s settings) settings {
+		s.drain = mode == ShutdownDrain
+		return s
+	}
+}
+
+// stop returns the chan that makes a stage stop right away. It is done, unless the stage drains, in which case only
+// abort, used to stop a stage regardless of shutdown mode, stops it, and the stage otherwise runs until its input
+// is closed.
+func (s settings) stop() <-chan struct{} {
+	if s.drain {
+		return s.abort
+	}
+	return s.done
+}
+
+// discard receives and drops what is left of in once a stage has stopped reading it before it was closed, e.g. Take
+// once it has taken enough. It only does so in drain mode, where the stages upstream would otherwise be left waiting
+// to send and never stop, and then runs until in is closed or the stage is aborted.
+func discard[A any](s settings, in <-chan A) {
+	if !s.drain || in == nil {
+		return
+	}
+	for {
+		select {
+		case <-s.abort:
+			return
+		case _, ok := <-in:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// source returns the settings for a stage that produces elements, rather than receiving them from a chan, which
+// stops once done is closed in every shutdown mode
+func (s settings) source() settings {
+	s.drain = false
+	return s
+}
+
+// withAbort returns settings where closing c stops the stage regardless of shutdown mode
+func (s settings) withAbort(c <-chan struct{}) settings {
+	s.done = SomeDone(s.done, c)
+	if s.abort == nil {
+		s.abort = c
+	} else {
+		s.abort = SomeDone(s.abort, c)
+	}
+	return s
+}
+
+// detachedContext passes on the values of its parent, but is never cancelled. It lets the functions of draining
+// stages finish their work after the context supplied with OpContext is cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Group keeps track of the stages created with OpGroup, so that one can wait for a whole pipeline to quiesce,
+// i.e. for all of its stages to have stopped.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	group := chanz.NewGroup()
+//	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpGroup(group)}
+//	... build the pipeline with opts ...
+//
+//	<-sigterm
+//	cancel()
+//	timeout, stop := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer stop()
+//	if err := group.Wait(timeout); err != nil {
+//	    log.Printf("pipeline did not drain in time, %d stages still running", group.Len())
+//	}
+type Group struct {
+	mu   sync.Mutex
+	live int
+	idle chan struct{} // closed when live is 0
+}
+
+// NewGroup creates an empty Group.
+func NewGroup() *Group {
+	idle := make(chan struct{})
+	close(idle)
+	return &Group{idle: idle}
+}
+
+// OpGroup creates an option that adds stages to group, from when they are created until they stop.
+func OpGroup(group *Group) Option {
+	return func(s settings) settings {
+		s.group = group
+		return s
+	}
+}
+
+// Len returns the number of stages in the group that have not yet stopped.
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.live
+}
+
+// Wait blocks until every stage in the group has stopped, and closed its outputs, and returns nil, or until ctx is
+// done, and returns ctx.Err().
+func (g *Group) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	idle := g.idle
+	g.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *Group) add(delta int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.live == 0 && delta > 0 {
+		g.idle = make(chan struct{})
+	}
+	g.live += delta
+	if g.live == 0 {
+		close(g.idle)
+	}
+}
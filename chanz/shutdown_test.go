@@ -0,0 +1,232 @@
+package chanz_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestShutdownDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpBuffer(2)}
+
+	in := make(chan int, 10)
+	for i := 1; i <= 10; i++ {
+		in <- i
+	}
+	mapped := chanz.Map(in, func(i int) int { return i * 10 }, opts...)
+	filtered := chanz.Filter(mapped, func(i int) bool { return i != 50 }, opts...)
+	batched := chanz.Batch(filtered, 3, time.Hour, opts...)
+
+	<-batched
+	cancel() // the stages keep going until in is closed
+	close(in)
+
+	res := chanz.Collect(batched, opts...)
+	exp := [][]int{{40, 60, 70}, {80, 90, 100}}
+	if !slicez.EqualBy(res, exp, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestShutdownAbort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 10)
+	for i := 1; i <= 10; i++ {
+		in <- i
+	}
+	group := chanz.NewGroup()
+	mapped := chanz.Map(in, func(i int) int { return i }, chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownAbort), chanz.OpGroup(group))
+
+	chanztest.ExpectSequence(t, mapped, time.Second, 1)
+	cancel()
+	wait, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := group.Wait(wait); err != nil {
+		t.Fatalf("expected, the stage to stop, but got %v", err)
+	}
+	chanztest.ExpectClosed(t, mapped, time.Second) // in is never closed, the stage stopped on done and dropped the rest
+}
+
+func TestShutdownDrainSource(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpClock(clock)}
+
+	ticks := chanz.Map(chanz.Interval(time.Second, opts...), func(t time.Time) int { return t.Second() }, opts...)
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, ticks, time.Second, 1)
+
+	cancel() // the source stops, which in turn closes the stage reading from it
+	chanztest.ExpectClosed(t, ticks, time.Second)
+}
+
+type ctxKey struct{}
+
+func TestShutdownDrainContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	in := make(chan int)
+	out := chanz.MapCtx(in, func(ctx context.Context, i int) string {
+		if ctx.Err() != nil {
+			return "cancelled"
+		}
+		v, _ := ctx.Value(ctxKey{}).(string)
+		return v
+	}, chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain))
+
+	cancel()
+	go func() {
+		in <- 1
+		close(in)
+	}()
+	chanztest.ExpectSequence(t, out, time.Second, "value")
+	chanztest.ExpectClosed(t, out, time.Second)
+}
+
+func TestGroup(t *testing.T) {
+	group := chanz.NewGroup()
+	if err := group.Wait(context.Background()); err != nil {
+		t.Logf("expected, an empty group to be idle, but got %v", err)
+		t.Fail()
+	}
+
+	in := make(chan int)
+	out := chanz.Filter(chanz.Map(in, func(i int) int { return i }, chanz.OpGroup(group)), func(i int) bool { return true }, chanz.OpGroup(group))
+	if group.Len() != 2 {
+		t.Logf("expected, 2, but got %v", group.Len())
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := group.Wait(ctx); err != context.DeadlineExceeded {
+		t.Logf("expected, %v, but got %v", context.DeadlineExceeded, err)
+		t.Fail()
+	}
+
+	close(in)
+	chanz.DropAll(out, true)
+	if err := group.Wait(context.Background()); err != nil || group.Len() != 0 {
+		t.Logf("expected, no live stages, but got %v, %v", group.Len(), err)
+		t.Fail()
+	}
+
+	// The group can be reused
+	out = chanz.Map(chanz.Generate(1), func(i int) int { return i }, chanz.OpGroup(group))
+	chanztest.ExpectSequence(t, out, time.Second, 1)
+	if err := group.Wait(context.Background()); err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestGroupDrain(t *testing.T) {
+	group := chanz.NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpGroup(group)}
+
+	lines := make(chan string)
+	out := chanz.Map(lines, func(l string) string { return l + "!" }, opts...)
+	go func() {
+		lines <- "a"
+		lines <- "b"
+		cancel()
+		close(lines)
+	}()
+
+	chanztest.ExpectSequence(t, out, time.Second, "a!")
+	chanztest.ExpectSequence(t, out, time.Second, "b!")
+	if err := group.Wait(context.Background()); err != nil {
+		t.Fatalf("expected, no error, but got %v", err)
+	}
+	select {
+	case e, ok := <-out:
+		if ok {
+			t.Fatalf("expected, out to be closed, but got %v", e)
+		}
+	default:
+		t.Fatal("expected, out to be closed once the group is idle")
+	}
+}
+
+func TestShutdownDrainTake(t *testing.T) {
+	group := chanz.NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpGroup(group)}
+
+	source := chanz.Generator(func(yield func(int)) {
+		for i := 0; ctx.Err() == nil; i++ {
+			yield(i)
+		}
+	}, opts...)
+	mapped := chanz.Map(source, func(i int) int { return i * 10 }, opts...)
+	res := chanz.Collect(chanz.Take(mapped, 2, opts...))
+	if !slicez.Equal(res, []int{0, 10}) {
+		t.Logf("expected, [0 10], but got %v", res)
+		t.Fail()
+	}
+
+	cancel() // Take discards the rest of mapped, so Map is not left waiting to send to it
+	wait, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := group.Wait(wait); err != nil {
+		t.Fatalf("expected, the pipeline to quiesce, but got %v with %d stages running", err, group.Len())
+	}
+}
+
+func TestShutdownDrainTimeout(t *testing.T) {
+	group := chanz.NewGroup()
+	clock := chanztest.NewFakeClock(start)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := []chanz.Option{chanz.OpContext(ctx), chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpGroup(group), chanz.OpClock(clock)}
+
+	in := make(chan int, 1)
+	in <- 1
+	res := chanz.Timeout(chanz.Map(in, func(i int) int { return i }, opts...), time.Second, opts...)
+	if r := <-res; r.OrEmpty() != 1 {
+		t.Fatalf("expected, 1, but got %v", r)
+	}
+	clock.BlockUntilArmed(t, 2) // the timer has been reset after the element
+	clock.Advance(time.Second)
+	if r := <-res; !errors.Is(r.Error(), chanz.ErrTimeout) {
+		t.Fatalf("expected, %v, but got %v", chanz.ErrTimeout, r)
+	}
+	chanztest.ExpectClosed(t, res, time.Second)
+
+	in <- 2 // Timeout discards it, so Map is not left waiting to send it
+	close(in)
+	wait, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := group.Wait(wait); err != nil {
+		t.Fatalf("expected, the pipeline to quiesce, but got %v with %d stages running", err, group.Len())
+	}
+}
+
+func TestShutdownDrainSplitResults(t *testing.T) {
+	group := chanz.NewGroup()
+	opts := []chanz.Option{chanz.OpShutdown(chanz.ShutdownDrain), chanz.OpGroup(group)}
+
+	results := chanz.MapErr(chanz.Generate("1", "x", "3", "4"), strconv.Atoi, opts...)
+	values, errs := chanz.SplitResults(results, append(opts, chanz.OpBuffer(1), chanz.OpFailFast(nil))...)
+	chanztest.ExpectSequence(t, values, time.Second, 1)
+	if err := <-errs; err == nil {
+		t.Fatal("expected, the error for x")
+	}
+	chanztest.ExpectClosed(t, values, time.Second)
+
+	// SplitResults discards the rest, so MapErr is not left waiting to send it
+	wait, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := group.Wait(wait); err != nil {
+		t.Fatalf("expected, the pipeline to quiesce, but got %v with %d stages running", err, group.Len())
+	}
+}
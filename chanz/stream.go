@@ -0,0 +1,178 @@
+package chanz
+
+import (
+	"context"
+	"time"
+)
+
+// Stream is a chan with a fluent API, where every method adds a stage to the chain and returns a new Stream.
+// All stages of a chain share the same options, given to StreamOf, and a context that is cancelled by Cancel,
+// which tears the whole chain down.
+// Since methods can not have type parameters, nor return a Stream of another instantiation, use MapStream and
+// BatchStream to change the element type of a Stream.
+//
+// Example:
+//
+//	stream := chanz.StreamOf(chanz.Generate(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), chanz.OpBuffer(10)).
+//	    Filter(func(n int) bool { return n%2 == 0 }).
+//	    Map(func(n int) int { return n * n }).
+//	    Take(3)
+//	result := stream.Collect()
+//	// result = []int{4, 16, 36}
+type Stream[A any] struct {
+	c       <-chan A
+	options []Option
+	cancel  context.CancelFunc
+}
+
+// StreamOf creates a Stream reading from c. The options are applied to every stage added to the Stream.
+// A context, cancelled by Stream.Cancel, is added to the options, combined with any context supplied with OpContext.
+// The Stream reads c in a stage of its own, which stops once the context is cancelled even while c is idle,
+// and in turn closes every stage added to the Stream.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	stream := chanz.StreamOf(events, chanz.OpContext(ctx), chanz.OpBuffer(100))
+func StreamOf[A any](c <-chan A, options ...Option) Stream[A] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	options = append(append([]Option{}, options...), OpContext(ctx))
+
+	return Stream[A]{
+		c:       relay(c, OpContext(ctx)(s)),
+		options: options,
+		cancel:  cancel,
+	}
+}
+
+// relay forwards the elements of c until it is closed or the stage is stopped, which, unlike for a stage ranging
+// over c, also happens while c is idle
+func relay[A any](c <-chan A, s settings) <-chan A {
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+		for {
+			select {
+			case <-s.stop():
+				return
+			case a, ok := <-c:
+				if !ok {
+					return
+				}
+				s.observe(EventReceived, 0)
+				if !send(s, out, a) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// then returns a Stream reading from c that shares options and cancel with s
+func then[A any, B any](s Stream[A], c <-chan B) Stream[B] {
+	return Stream[B]{c: c, options: s.options, cancel: s.cancel}
+}
+
+// Chan returns the chan of the last stage of the Stream, breaking the chain.
+func (s Stream[A]) Chan() <-chan A {
+	return s.c
+}
+
+// Cancel stops every stage of the Stream, closing their chans, also while the chan the Stream was created from is idle.
+// Like the cancel func of a context, Cancel should be called once a Stream that is not read to its end, using
+// Collect or ForEach, is no longer used.
+// With OpShutdown(ShutdownDrain) the stages instead run until the chan the Stream was created from is closed.
+func (s Stream[A]) Cancel() {
+	s.cancel()
+}
+
+// Map adds a Map stage to the Stream. Use MapStream to map to another type.
+func (s Stream[A]) Map(mapper func(a A) A) Stream[A] {
+	return then(s, MapWith[A, A](s.options...)(s.c, mapper))
+}
+
+// Filter adds a Filter stage to the Stream, keeping the elements for which include returns true.
+func (s Stream[A]) Filter(include func(a A) bool) Stream[A] {
+	return then(s, FilterWith[A](s.options...)(s.c, include))
+}
+
+// Peek adds a Peek stage to the Stream, calling apply on every element passing through.
+func (s Stream[A]) Peek(apply func(a A)) Stream[A] {
+	return then(s, PeekWith[A](s.options...)(s.c, apply))
+}
+
+// Take adds a Take stage to the Stream, passing on the first i elements.
+func (s Stream[A]) Take(i int) Stream[A] {
+	return then(s, TakeWith[A](s.options...)(s.c, i))
+}
+
+// TakeWhile adds a TakeWhile stage to the Stream, passing on elements until take returns false.
+func (s Stream[A]) TakeWhile(take func(a A) bool) Stream[A] {
+	return then(s, TakeWhileWith[A](s.options...)(s.c, take))
+}
+
+// Drop adds a Drop stage to the Stream, dropping the first i elements.
+func (s Stream[A]) Drop(i int) Stream[A] {
+	return then(s, DropWith[A](s.options...)(s.c, i))
+}
+
+// DropWhile adds a DropWhile stage to the Stream, dropping elements until drop returns false.
+func (s Stream[A]) DropWhile(drop func(a A) bool) Stream[A] {
+	return then(s, DropWhileWith[A](s.options...)(s.c, drop))
+}
+
+// Buffer adds a stage with a buffer of size to the Stream, letting upstream stages run ahead of downstream
+// stages by up to size elements.
+func (s Stream[A]) Buffer(size int) Stream[A] {
+	options := append(append([]Option{}, s.options...), OpBuffer(size))
+	return then(s, MapWith[A, A](options...)(s.c, func(a A) A { return a }))
+}
+
+// Collect reads the Stream until it is closed, or cancelled, and returns the elements, breaking the chain.
+func (s Stream[A]) Collect() []A {
+	defer s.cancel()
+	return Collect(s.c, s.options...)
+}
+
+// ForEach calls apply on every element of the Stream until it is closed, or cancelled, breaking the chain.
+func (s Stream[A]) ForEach(apply func(a A)) {
+	defer s.cancel()
+	for a := range s.c {
+		apply(a)
+	}
+}
+
+// MapStream adds a Map stage to the Stream, changing the element type. It is a function, not a method,
+// since methods can not have type parameters.
+//
+// Example:
+//
+//	lengths := chanz.MapStream(chanz.StreamOf(words), func(w string) int { return len(w) }).
+//	    Filter(func(n int) bool { return n > 3 }).
+//	    Collect()
+func MapStream[A any, B any](s Stream[A], mapper func(a A) B) Stream[B] {
+	return then(s, MapWith[A, B](s.options...)(s.c, mapper))
+}
+
+// BatchStream adds a Batch stage to the Stream, grouping elements into slices of at most maxSize elements,
+// or what has been received within maxWait of the first element of a batch. It is a function, not a method,
+// since a method of Stream[A] can not return a Stream[[]A].
+//
+// Example:
+//
+//	batches := chanz.BatchStream(chanz.StreamOf(rows, chanz.OpContext(ctx)), 500, 100*time.Millisecond)
+//	batches.ForEach(func(batch []Row) { db.Insert(batch) })
+func BatchStream[A any](s Stream[A], maxSize int, maxWait time.Duration) Stream[[]A] {
+	return then(s, BatchWith[A](s.options...)(s.c, maxSize, maxWait))
+}
@@ -0,0 +1,127 @@
+package chanz
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/slicez"
+)
+
+func TestStream(t *testing.T) {
+	res := StreamOf(Generate(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)).
+		Filter(func(n int) bool { return n%2 == 0 }).
+		Map(func(n int) int { return n * n }).
+		Drop(1).
+		Take(3).
+		Collect()
+
+	exp := []int{16, 36, 64}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestStreamWhile(t *testing.T) {
+	var peeked []int
+	res := StreamOf(Generate(1, 2, 3, 4, 5, 1)).
+		DropWhile(func(n int) bool { return n < 2 }).
+		TakeWhile(func(n int) bool { return n < 5 }).
+		Peek(func(n int) { peeked = append(peeked, n) }).
+		Buffer(10).
+		Collect()
+
+	exp := []int{2, 3, 4}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+	if !slicez.Equal(peeked, exp) {
+		t.Logf("expected, %v, but got %v", exp, peeked)
+		t.Fail()
+	}
+}
+
+func TestMapStream(t *testing.T) {
+	res := MapStream(StreamOf(Generate(1, 20, 300)), strconv.Itoa).
+		Filter(func(s string) bool { return len(s) > 1 }).
+		Collect()
+
+	exp := []string{"20", "300"}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestBatchStream(t *testing.T) {
+	var res [][]int
+	BatchStream(StreamOf(Generate(1, 2, 3, 4, 5)), 2, time.Hour).
+		ForEach(func(batch []int) { res = append(res, batch) })
+
+	exp := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slicez.EqualBy(res, exp, slicez.Equal[int]) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	source := Generator(func(yield func(int)) {
+		for i := 0; i < 1000; i++ {
+			yield(i)
+		}
+	}, OpBuffer(1))
+	defer DropAll(source, true)
+
+	stream := StreamOf(source).
+		Map(func(n int) int { return n + 1 }).
+		Filter(func(n int) bool { return n%2 == 0 })
+	c := stream.Chan()
+
+	expectNext(t, c, 2)
+	stream.Cancel()
+
+	// A few elements may already be on their way, but the chain must close
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected, stream to be closed after cancel")
+		}
+	}
+}
+
+func TestStreamCancelIdle(t *testing.T) {
+	stream := StreamOf(make(chan int)).
+		Filter(func(n int) bool { return n%2 == 0 }).
+		Take(5)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stream.ForEach(func(int) {})
+	}()
+
+	stream.Cancel()
+	expectClosed(t, done) // even though the source never receives anything
+}
+
+func TestStreamContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := StreamOf(Generator(func(yield func(int)) {
+		for i := 0; i < 1000; i++ {
+			yield(i)
+		}
+	}), OpContext(ctx)).Map(func(n int) int { return n })
+
+	expectNext(t, stream.Chan(), 0)
+	cancel()
+	DropAll(stream.Chan(), false)
+}
@@ -0,0 +1,234 @@
+package chanz
+
+import (
+	"time"
+)
+
+// Throttle takes a chan and returns a chan that lets elements through at a rate of at most rate elements per second.
+// It is a token bucket limiter, allowing bursts of up to burst elements after a quiet period. A burst < 1 is treated as 1,
+// and a rate <= 0 means that no limit is applied.
+// Elements are not dropped, a throttled element is held until a token is available, which in turn stalls the input.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	requests := chanz.Generate(reqs...)
+//	limited := chanz.Throttle(requests, 10, 5) // 10 requests per second, bursts of 5
+//	responses := chanz.Map(limited, call)
+func Throttle[A any](in <-chan A, rate float64, burst int, options ...Option) <-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		clock := s.getClock()
+		tokens := float64(burst)
+		last := clock.Now()
+		refill := func() {
+			now := clock.Now()
+			tokens += now.Sub(last).Seconds() * rate
+			if tokens > float64(burst) {
+				tokens = float64(burst)
+			}
+			last = now
+		}
+
+		for e := range in {
+			s.observe(EventReceived, 0)
+			if rate > 0 {
+				refill()
+				if tokens < 1 {
+					wait := time.Duration((1 - tokens) / rate * float64(time.Second))
+					timer := clock.NewTimer(wait)
+					select {
+					case <-s.stop():
+						timer.Stop()
+						return
+					case <-timer.C():
+					}
+					refill()
+				}
+				tokens -= 1
+			}
+
+			if !send(s, out, e) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ThrottleWith returns a configured Throttle function closure.
+// Allows creating reusable rate limiters with preset options.
+//
+// Example:
+//
+//	limiter := chanz.ThrottleWith[Request](chanz.OpContext(ctx))
+//	limited := limiter(requests, 10, 1)
+func ThrottleWith[A any](options ...Option) func(in <-chan A, rate float64, burst int) <-chan A {
+	return func(in <-chan A, rate float64, burst int) <-chan A {
+		return Throttle(in, rate, burst, options...)
+	}
+}
+
+// Debounce takes a chan and returns a chan that only emits an element once no newer element has been received for
+// the duration of quiet. Elements followed by a newer element within quiet are dropped, which is useful for bursty
+// event streams where only the final state matters. When "in" is closed the pending element, if any, is emitted.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	changes := watchConfig()
+//	settled := chanz.Debounce(changes, 500*time.Millisecond)
+//	for cfg := range settled {
+//	    reload(cfg)
+//	}
+func Debounce[A any](in <-chan A, quiet time.Duration, options ...Option) <-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		clock := s.getClock()
+		var timer Timer
+		var timeout <-chan time.Time
+		var pending A
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-s.stop():
+				return
+			case e, ok := <-in:
+				if !ok {
+					if timeout != nil {
+						send(s, out, pending)
+					}
+					return
+				}
+				s.observe(EventReceived, 0)
+				pending = e
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = clock.NewTimer(quiet)
+				timeout = timer.C()
+			case <-timeout:
+				timeout = nil
+				if !send(s, out, pending) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// DebounceWith returns a configured Debounce function closure.
+// Allows creating reusable debouncers with preset options.
+//
+// Example:
+//
+//	debouncer := chanz.DebounceWith[Event](chanz.OpBuffer(1))
+//	settled := debouncer(events, time.Second)
+func DebounceWith[A any](options ...Option) func(in <-chan A, quiet time.Duration) <-chan A {
+	return func(in <-chan A, quiet time.Duration) <-chan A {
+		return Debounce(in, quiet, options...)
+	}
+}
+
+// Sample takes a chan and returns a chan that emits the latest element received, once every interval.
+// Nothing is emitted for an interval in which no new element was received, and elements received in between are dropped.
+// When "in" is closed the latest element, if not yet emitted, is emitted. Sample panics if interval <= 0.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	ticks := priceFeed()
+//	perSecond := chanz.Sample(ticks, time.Second)
+//	for price := range perSecond {
+//	    render(price)
+//	}
+func Sample[A any](in <-chan A, interval time.Duration, options ...Option) <-chan A {
+	if interval <= 0 {
+		panic("chanz: non-positive interval for Sample")
+	}
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		ticker := s.getClock().NewTicker(interval)
+		defer ticker.Stop()
+
+		var latest A
+		var fresh bool
+		for {
+			select {
+			case <-s.stop():
+				return
+			case e, ok := <-in:
+				if !ok {
+					if fresh {
+						send(s, out, latest)
+					}
+					return
+				}
+				s.observe(EventReceived, 0)
+				latest = e
+				fresh = true
+			case <-ticker.C():
+				if !fresh {
+					continue
+				}
+				fresh = false
+				if !send(s, out, latest) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// SampleWith returns a configured Sample function closure.
+// Allows creating reusable samplers with preset options.
+//
+// Example:
+//
+//	sampler := chanz.SampleWith[float64](chanz.OpContext(ctx))
+//	perSecond := sampler(prices, time.Second)
+func SampleWith[A any](options ...Option) func(in <-chan A, interval time.Duration) <-chan A {
+	return func(in <-chan A, interval time.Duration) <-chan A {
+		return Sample(in, interval, options...)
+	}
+}
@@ -0,0 +1,124 @@
+package chanz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestThrottle(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int, 10)
+	for i := 1; i <= 4; i++ {
+		in <- i
+	}
+	limited := chanz.Throttle(in, 2, 2, chanz.OpClock(clock))
+
+	// A full bucket lets a burst through
+	chanztest.ExpectSequence(t, limited, time.Second, 1)
+	chanztest.ExpectSequence(t, limited, time.Second, 2)
+	chanztest.ExpectNothing(t, limited, chanztest.ShortWait)
+
+	// One token every half second
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(500 * time.Millisecond)
+	chanztest.ExpectSequence(t, limited, time.Second, 3)
+
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(499 * time.Millisecond)
+	chanztest.ExpectNothing(t, limited, chanztest.ShortWait)
+	clock.Advance(time.Millisecond)
+	chanztest.ExpectSequence(t, limited, time.Second, 4)
+
+	close(in)
+	chanztest.ExpectClosed(t, limited, time.Second)
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	res := chanz.Collect(chanz.Throttle(chanz.Generate(1, 2, 3), 0, 1, chanz.OpClock(chanztest.NewFakeClock(start))))
+	exp := []int{1, 2, 3}
+	if !slicez.Equal(exp, res) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
+
+func TestThrottleContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	limited := chanz.Throttle(in, 1, 1, chanz.OpClock(chanztest.NewFakeClock(start)), chanz.OpContext(ctx))
+
+	chanztest.ExpectSequence(t, limited, time.Second, 1)
+	cancel()
+	chanztest.ExpectClosed(t, limited, time.Second)
+}
+
+func TestDebounce(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int)
+	settled := chanz.Debounce(in, time.Second, chanz.OpClock(clock))
+
+	in <- 1
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(900 * time.Millisecond)
+	in <- 2
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(900 * time.Millisecond)
+	chanztest.ExpectNothing(t, settled, chanztest.ShortWait)
+
+	clock.Advance(100 * time.Millisecond)
+	chanztest.ExpectSequence(t, settled, time.Second, 2)
+
+	in <- 3
+	close(in)
+	chanztest.ExpectSequence(t, settled, time.Second, 3)
+	chanztest.ExpectClosed(t, settled, time.Second)
+}
+
+func TestSample(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int)
+	sampled := chanz.Sample(in, time.Second, chanz.OpClock(clock))
+
+	clock.BlockUntilArmed(t, 1)
+	in <- 1
+	in <- 2
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, sampled, time.Second, 2)
+
+	// Nothing new, nothing emitted
+	clock.Advance(time.Second)
+	chanztest.ExpectNothing(t, sampled, chanztest.ShortWait)
+
+	in <- 3
+	close(in)
+	chanztest.ExpectSequence(t, sampled, time.Second, 3)
+	chanztest.ExpectClosed(t, sampled, time.Second)
+}
+
+func TestSamplePanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Log("expected, panic for a non-positive interval")
+			t.Fail()
+		}
+	}()
+	chanz.Sample(make(chan int), 0)
+}
+
+func TestThrottleWith(t *testing.T) {
+	limiter := chanz.ThrottleWith[int](chanz.OpBuffer(1))
+	debouncer := chanz.DebounceWith[int](chanz.OpBuffer(1))
+	sampler := chanz.SampleWith[int](chanz.OpBuffer(1))
+
+	res := chanz.Collect(sampler(debouncer(limiter(chanz.Generate(1, 2, 3), 1000, 3), time.Millisecond), time.Millisecond))
+	if len(res) != 1 || res[0] != 3 {
+		t.Errorf("expected only the last element, got %v", res)
+	}
+}
@@ -0,0 +1,203 @@
+package chanz
+
+import (
+	"errors"
+	"time"
+
+	"github.com/modfin/henry/mon"
+)
+
+// ErrTimeout is emitted by Timeout when no element has been received in time.
+var ErrTimeout = errors.New("chanz: timeout waiting for element")
+
+// Timeout takes a chan and returns a chan where every element is passed on as Ok. If no element is received within d,
+// counted from the start and from each element being passed on, Err(ErrTimeout) is emitted and the chan is closed.
+// This detects an upstream that has gone dead, rather than one that is slow.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	prices := chanz.Timeout(feed, 30*time.Second)
+//	for r := range prices {
+//	    price, err := r.Get()
+//	    if errors.Is(err, chanz.ErrTimeout) {
+//	        reconnect()
+//	    }
+//	    ...
+//	}
+func Timeout[A any](in <-chan A, d time.Duration, options ...Option) <-chan mon.Result[A] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan mon.Result[A], s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
+		timedOut := timeout(in, d, s, func(a A) bool {
+			return send(s, out, mon.Ok(a))
+		})
+		if timedOut {
+			send(s, out, mon.Err[A](ErrTimeout))
+		}
+	}()
+	return out
+}
+
+// timeout passes every element of in to emit until in is closed, emit returns false, done is closed or no element
+// is received within d, in which case it returns true
+func timeout[A any](in <-chan A, d time.Duration, s settings, emit func(a A) bool) (timedOut bool) {
+	clock := s.getClock()
+	timer := clock.NewTimer(d)
+	defer func() {
+		timer.Stop()
+	}()
+
+	for {
+		select {
+		case <-s.stop():
+			return false
+		case e, ok := <-in:
+			if !ok {
+				return false
+			}
+			s.observe(EventReceived, 0)
+			if !emit(e) {
+				return false
+			}
+			timer.Stop()
+			timer = clock.NewTimer(d)
+		case <-timer.C():
+			return true
+		}
+	}
+}
+
+// TimeoutWith returns a configured Timeout function closure.
+// Allows creating reusable timeouts with preset options.
+//
+// Example:
+//
+//	watchdog := chanz.TimeoutWith[Price](chanz.OpContext(ctx))
+//	prices := watchdog(feed, 30*time.Second)
+func TimeoutWith[A any](options ...Option) func(in <-chan A, d time.Duration) <-chan mon.Result[A] {
+	return func(in <-chan A, d time.Duration) <-chan mon.Result[A] {
+		return Timeout(in, d, options...)
+	}
+}
+
+// TimeoutClose is like Timeout, but passes elements on as they are and only closes the chan if no element is
+// received within d.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	for msg := range chanz.TimeoutClose(session, 5*time.Minute) {
+//	    handle(msg)
+//	}
+//	// the session ended or was idle for five minutes
+func TimeoutClose[A any](in <-chan A, d time.Duration, options ...Option) <-chan A {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan A, s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() {
+			close(out)
+			discard(s, in)
+		})
+		timeout(in, d, s, func(a A) bool {
+			return send(s, out, a)
+		})
+	}()
+	return out
+}
+
+// TimeoutCloseWith returns a configured TimeoutClose function closure.
+// Allows creating reusable timeouts with preset options.
+func TimeoutCloseWith[A any](options ...Option) func(in <-chan A, d time.Duration) <-chan A {
+	return func(in <-chan A, d time.Duration) <-chan A {
+		return TimeoutClose(in, d, options...)
+	}
+}
+
+// Heartbeat takes a chan and returns a chan where every element is passed on as Some. Whenever no element has been
+// received for the duration of interval, a None marker is emitted, and then again for every interval the chan
+// stays quiet. This lets consumers tell a quiet stream from a dead one without a timeout of their own.
+// Time is measured by the clock supplied with OpClock, default is the wall clock.
+// The return chan has a buffer of buffer size supplied in input Option, default is 0.
+// It will stop once "in", "done" channel is closed or the context.Done is closed, which is supplied in Option
+//
+// Example:
+//
+//	for o := range chanz.Heartbeat(trades, 10*time.Second) {
+//	    trade, ok := o.Get()
+//	    if !ok {
+//	        log.Println("no trades for 10s, feed still alive")
+//	        continue
+//	    }
+//	    handle(trade)
+//	}
+func Heartbeat[A any](in <-chan A, interval time.Duration, options ...Option) <-chan mon.Option[A] {
+	var s settings
+	for _, o := range options {
+		s = o(s)
+	}
+
+	out := make(chan mon.Option[A], s.buffer)
+	s.started()
+	go func() {
+		defer s.finished(func() { close(out) })
+
+		clock := s.getClock()
+		timer := clock.NewTimer(interval)
+		defer func() {
+			timer.Stop()
+		}()
+
+		for {
+			select {
+			case <-s.stop():
+				return
+			case e, ok := <-in:
+				if !ok {
+					return
+				}
+				s.observe(EventReceived, 0)
+				if !send(s, out, mon.Some(e)) {
+					return
+				}
+			case <-timer.C():
+				if !send(s, out, mon.None[A]()) {
+					return
+				}
+			}
+			timer.Stop()
+			timer = clock.NewTimer(interval)
+		}
+	}()
+	return out
+}
+
+// HeartbeatWith returns a configured Heartbeat function closure.
+// Allows creating reusable heartbeats with preset options.
+//
+// Example:
+//
+//	heartbeat := chanz.HeartbeatWith[Trade](chanz.OpBuffer(10))
+//	trades := heartbeat(feed, 10*time.Second)
+func HeartbeatWith[A any](options ...Option) func(in <-chan A, interval time.Duration) <-chan mon.Option[A] {
+	return func(in <-chan A, interval time.Duration) <-chan mon.Option[A] {
+		return Heartbeat(in, interval, options...)
+	}
+}
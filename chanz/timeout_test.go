@@ -0,0 +1,110 @@
+package chanz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+	"github.com/modfin/henry/mon"
+	"github.com/modfin/henry/slicez"
+)
+
+func TestTimeout(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int)
+	res := chanz.Timeout(in, time.Second, chanz.OpClock(clock))
+
+	clock.BlockUntilArmed(t, 1)
+	clock.Advance(900 * time.Millisecond)
+	in <- 1
+	if v := (<-res).MustGet(); v != 1 {
+		t.Logf("expected, 1, but got %v", v)
+		t.Fail()
+	}
+
+	// The timeout restarts after every element
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(900 * time.Millisecond)
+	chanztest.ExpectNothing(t, res, chanztest.ShortWait)
+	clock.Advance(100 * time.Millisecond)
+
+	r := <-res
+	if r.Error() != chanz.ErrTimeout {
+		t.Logf("expected, %v, but got %v", chanz.ErrTimeout, r)
+		t.Fail()
+	}
+	chanztest.ExpectClosed(t, res, time.Second)
+}
+
+func TestTimeoutClosedInput(t *testing.T) {
+	res := chanz.Collect(chanz.Timeout(chanz.Generate(1, 2, 3), time.Hour, chanz.OpClock(chanztest.NewFakeClock(start))))
+	values := slicez.Map(res, func(r mon.Result[int]) int { return r.MustGet() })
+	exp := []int{1, 2, 3}
+	if !slicez.Equal(values, exp) {
+		t.Logf("expected, %v, but got %v", exp, values)
+		t.Fail()
+	}
+}
+
+func TestTimeoutClose(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan int)
+	res := chanz.TimeoutClose(in, time.Second, chanz.OpClock(clock))
+
+	clock.BlockUntilArmed(t, 1)
+	in <- 1
+	chanztest.ExpectSequence(t, res, time.Second, 1)
+
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(time.Second)
+	chanztest.ExpectClosed(t, res, time.Second)
+}
+
+func TestTimeoutContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	res := chanz.Timeout(make(chan int), time.Hour, chanz.OpClock(chanztest.NewFakeClock(start)), chanz.OpContext(ctx))
+	cancel()
+	chanztest.ExpectClosed(t, res, time.Second)
+}
+
+func TestHeartbeat(t *testing.T) {
+	clock := chanztest.NewFakeClock(start)
+	in := make(chan string)
+	res := chanz.Heartbeat(in, time.Second, chanz.OpClock(clock))
+
+	clock.BlockUntilArmed(t, 1)
+	in <- "a"
+	chanztest.ExpectSequence(t, res, time.Second, mon.Some("a"))
+
+	// Quiet for a second, then for another
+	clock.BlockUntilArmed(t, 2)
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, res, time.Second, mon.None[string]())
+	clock.BlockUntilArmed(t, 3)
+	clock.Advance(time.Second)
+	chanztest.ExpectSequence(t, res, time.Second, mon.None[string]())
+
+	// An element resets the interval
+	clock.BlockUntilArmed(t, 4)
+	clock.Advance(500 * time.Millisecond)
+	in <- "b"
+	chanztest.ExpectSequence(t, res, time.Second, mon.Some("b"))
+	clock.BlockUntilArmed(t, 5)
+	clock.Advance(500 * time.Millisecond)
+	chanztest.ExpectNothing(t, res, chanztest.ShortWait)
+
+	close(in)
+	chanztest.ExpectClosed(t, res, time.Second)
+}
+
+func TestHeartbeatWith(t *testing.T) {
+	heartbeat := chanz.HeartbeatWith[int](chanz.OpClock(chanztest.NewFakeClock(start)), chanz.OpBuffer(3))
+	res := chanz.Collect(heartbeat(chanz.Generate(1, 2, 3), time.Second))
+	exp := []mon.Option[int]{mon.Some(1), mon.Some(2), mon.Some(3)}
+	if !slicez.Equal(res, exp) {
+		t.Logf("expected, %v, but got %v", exp, res)
+		t.Fail()
+	}
+}
@@ -0,0 +1,193 @@
+package chanz
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stage describes a stage that is tracked by a Tracker.
+type Stage struct {
+	ID      uint64    // Unique within the Tracker, in order of creation
+	Name    string    // Name supplied with OpName or OpObserver, or else the chanz function called to create the stage
+	Created time.Time // When the stage was created
+	Stack   string    // Stack trace of where the stage was created
+}
+
+// Tracker records the stages that are running, from when they are created until they stop, together with where they
+// were created. A stage that never stops, because its output is not drained and it has no done channel, leaks its
+// goroutine, and in a long-lived server abandoned pipelines pile up, which Stages and Check reveal.
+// Tracking captures a stack trace for every stage, so it is opt-in, either with OpTracker per stage or for every
+// stage with SetTracker. The goroutines of Go, Future.ToChan and Broker.Subscribe are tracked as stages too, though
+// Go, which takes no options, only by the tracker set with SetTracker.
+//
+// Example:
+//
+//	tracker := chanz.NewTracker()
+//	chanz.SetTracker(tracker)
+//
+//	http.HandleFunc("/debug/stages", func(w http.ResponseWriter, r *http.Request) {
+//	    for _, st := range tracker.Stages() {
+//	        fmt.Fprintf(w, "%d %s, running for %v\n%s\n", st.ID, st.Name, time.Since(st.Created), st.Stack)
+//	    }
+//	})
+//
+//	// at shutdown
+//	if err := tracker.Check(); err != nil {
+//	    log.Println(err)
+//	}
+type Tracker struct {
+	mu     sync.Mutex
+	stages map[uint64]Stage
+	nextID uint64
+}
+
+// NewTracker creates a Tracker without any stages.
+func NewTracker() *Tracker {
+	return &Tracker{stages: map[uint64]Stage{}}
+}
+
+// OpTracker creates an option that makes tracker record the stage, overriding the tracker set with SetTracker.
+func OpTracker(tracker *Tracker) Option {
+	return func(s settings) settings {
+		s.tracker = tracker
+		return s
+	}
+}
+
+// OpName creates an option that names the stage, as reported to the observer and by the tracker.
+func OpName(name string) Option {
+	return func(s settings) settings {
+		s.name = name
+		return s
+	}
+}
+
+// defaultTracker holds the *Tracker set with SetTracker
+var defaultTracker atomic.Value
+
+// SetTracker makes tracker record every stage that is created from now on, unless another tracker is supplied with
+// OpTracker. A nil tracker turns tracking off, which is the default.
+func SetTracker(tracker *Tracker) {
+	defaultTracker.Store(tracker)
+}
+
+// Stages returns the stages that are running, in order of creation.
+func (t *Tracker) Stages() []Stage {
+	t.mu.Lock()
+	stages := make([]Stage, 0, len(t.stages))
+	for _, st := range t.stages {
+		stages = append(stages, st)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(stages, func(i, j int) bool {
+		return stages[i].ID < stages[j].ID
+	})
+	return stages
+}
+
+// Len returns the number of stages that are running.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.stages)
+}
+
+// Check returns an error listing the stages that are running, with where they were created, or nil if there are none.
+// Stages stop asynchronously, so a pipeline that was just cancelled may still be listed, see Group to wait for it.
+func (t *Tracker) Check() error {
+	stages := t.Stages()
+	if len(stages) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "chanz: %d stages still running", len(stages))
+	for _, st := range stages {
+		fmt.Fprintf(&b, "\n\nstage %d, %s, created %s:\n%s", st.ID, st.Name, st.Created.Format(time.RFC3339), st.Stack)
+	}
+	return errors.New(b.String())
+}
+
+// add records a stage created by the caller of started, and returns its id
+func (t *Tracker) add(name string) uint64 {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs) // skip Callers, add and started
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack strings.Builder
+	var function string // the outermost chanz function, i.e. the one called from outside of the package
+	inside := true
+	for {
+		f, more := frames.Next()
+		if inside && strings.HasPrefix(f.Function, chanzPackage) {
+			function = f.Function
+		} else {
+			inside = false
+		}
+		fmt.Fprintf(&stack, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	if name == "" {
+		name = stageName(function)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.stages[t.nextID] = Stage{ID: t.nextID, Name: name, Created: time.Now(), Stack: stack.String()}
+	return t.nextID
+}
+
+func (t *Tracker) remove(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.stages, id)
+}
+
+// chanzPackage is the prefix of the names of functions in this package
+const chanzPackage = "github.com/modfin/henry/chanz."
+
+// stageName returns the name of the chanz function, e.g. "MapWith" for "github.com/modfin/henry/chanz.MapWith[...].func1"
+// and "Stream.Buffer" for "github.com/modfin/henry/chanz.Stream[...].Buffer"
+func stageName(function string) string {
+	name := strings.TrimPrefix(function, chanzPackage)
+	name = strings.NewReplacer("[...]", "", "(*", "", ")", "").Replace(name)
+	var parts []string
+	for _, part := range strings.Split(name, ".") {
+		if strings.HasPrefix(part, "func") { // a closure within the function
+			break
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tracked is a stage recorded by a tracker
+type tracked struct {
+	tracker *Tracker
+	id      uint64
+}
+
+// started registers a stage with its group and tracker, if any. It sets s.tracked, so it must be called before
+// any goroutine of the stage, which reads s, is started. The stage calls finished once it stops.
+func (s *settings) started() {
+	if s.group != nil {
+		s.group.add(1)
+	}
+	tracker := s.tracker
+	if tracker == nil {
+		tracker, _ = defaultTracker.Load().(*Tracker)
+	}
+	if tracker != nil {
+		s.tracked = &tracked{tracker: tracker, id: tracker.add(s.name)}
+	}
+}
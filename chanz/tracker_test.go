@@ -0,0 +1,164 @@
+package chanz_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modfin/henry/chanz"
+	"github.com/modfin/henry/chanz/chanztest"
+)
+
+// waitStages waits for the tracker to have n stages
+func waitStages(t *testing.T, tracker *chanz.Tracker, n int) {
+	t.Helper()
+	for i := 0; i < 1000 && tracker.Len() != n; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if tracker.Len() != n {
+		t.Fatalf("expected %d stages, got %d", n, tracker.Len())
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := chanz.NewTracker()
+	in := make(chan int)
+	mapped := chanz.Map(in, func(i int) int { return i }, chanz.OpTracker(tracker))
+	filtered := chanz.Filter(mapped, func(i int) bool { return true }, chanz.OpTracker(tracker), chanz.OpName("keep"))
+
+	stages := tracker.Stages()
+	if len(stages) != 2 {
+		t.Fatalf("expected, 2 stages, but got %v", stages)
+	}
+	if stages[0].Name != "Map" || stages[1].Name != "keep" || stages[0].ID >= stages[1].ID {
+		t.Logf("expected, Map and keep, in order, but got %v and %v", stages[0].Name, stages[1].Name)
+		t.Fail()
+	}
+	if !strings.Contains(stages[0].Stack, "tracker_test.go") || !strings.Contains(stages[0].Stack, "TestTracker") {
+		t.Logf("expected, the stack of where the stage was created, but got %v", stages[0].Stack)
+		t.Fail()
+	}
+	if time.Since(stages[0].Created) > time.Minute {
+		t.Logf("expected, created now, but got %v", stages[0].Created)
+		t.Fail()
+	}
+
+	err := tracker.Check()
+	if err == nil || !strings.Contains(err.Error(), "2 stages still running") || !strings.Contains(err.Error(), "keep") {
+		t.Logf("expected, the running stages, but got %v", err)
+		t.Fail()
+	}
+
+	close(in)
+	chanztest.ExpectClosed(t, filtered, time.Second)
+	waitStages(t, tracker, 0)
+	if err := tracker.Check(); err != nil {
+		t.Logf("expected, no error, but got %v", err)
+		t.Fail()
+	}
+}
+
+func TestSetTracker(t *testing.T) {
+	tracker := chanz.NewTracker()
+	chanz.SetTracker(tracker)
+	defer chanz.SetTracker(nil)
+
+	other := chanz.NewTracker()
+	generated := chanz.Generate(1)
+	taken := chanz.Take(generated, 1, chanz.OpTracker(other))
+
+	if stages := tracker.Stages(); len(stages) != 1 || stages[0].Name != "Generate" {
+		t.Logf("expected, Generate, but got %v", stages)
+		t.Fail()
+	}
+	if stages := other.Stages(); len(stages) != 1 || stages[0].Name != "Take" {
+		t.Logf("expected, Take, but got %v", stages)
+		t.Fail()
+	}
+
+	chanztest.ExpectSequence(t, taken, time.Second, 1)
+	chanztest.ExpectClosed(t, taken, time.Second)
+	waitStages(t, tracker, 0)
+	waitStages(t, other, 0)
+
+	chanz.SetTracker(nil)
+	chanz.DropAll(chanz.Generate(1), false)
+	if tracker.Len() != 0 {
+		t.Logf("expected, no stages after tracking is turned off, but got %v", tracker.Stages())
+		t.Fail()
+	}
+}
+
+func TestTrackerName(t *testing.T) {
+	tracker := chanz.NewTracker()
+	in := make(chan int)
+	op := chanz.OpTracker(tracker)
+
+	chanz.Map(in, func(i int) int { return i }, op)
+	chanz.MapWith[int, int](op)(in, func(i int) int { return i })
+	chanz.Peek(in, func(i int) {}, op)
+	chanz.Scan(in, func(acc, i int) int { return acc + i }, 0, op)
+	chanz.StreamOf(in, op).Buffer(1)
+	messages := chanz.NewBroker[int]()
+	messages.Subscribe("prices", op, chanz.OpDone(make(chan struct{})))
+
+	exp := []string{"Map", "MapWith", "Peek", "Scan", "StreamOf", "Stream.Buffer", "Broker.Subscribe"}
+	stages := tracker.Stages()
+	if len(stages) != len(exp) {
+		t.Fatalf("expected, %v, but got %v", exp, stages)
+	}
+	for i, st := range stages {
+		if st.Name != exp[i] {
+			t.Logf("expected, %v, but got %v", exp[i], st.Name)
+			t.Fail()
+		}
+	}
+
+	close(in)
+	messages.Close()
+	waitStages(t, tracker, 0)
+}
+
+func TestTrackerFuture(t *testing.T) {
+	tracker := chanz.NewTracker()
+	chanz.SetTracker(tracker)
+	defer chanz.SetTracker(nil)
+
+	release := make(chan struct{})
+	future := chanz.Go(func() (int, error) {
+		<-release
+		return 1, nil
+	})
+	results := future.ToChan(chanz.OpTracker(tracker))
+	if stages := tracker.Stages(); len(stages) != 2 || stages[0].Name != "Go" || stages[1].Name != "Future.ToChan" {
+		t.Logf("expected, Go and Future.ToChan, but got %v", stages)
+		t.Fail()
+	}
+
+	close(release)
+	<-results
+	waitStages(t, tracker, 0)
+}
+
+// TestTrackerRace covers the stages that run more than one goroutine, run it with -race
+func TestTrackerRace(t *testing.T) {
+	tracker := chanz.NewTracker()
+	op := chanz.OpTracker(tracker)
+	id := func(i int) int { return i }
+
+	chanz.Collect(chanz.ParallelMap(chanz.Generate(1, 2, 3, 4, 5, 6, 7, 8), id, 4, op))
+	chanz.Collect(chanz.ParallelMap(chanz.Generate(1, 2, 3, 4, 5, 6, 7, 8), id, 4, op, chanz.OpOrdered()))
+	chanz.Collect(chanz.FanInWith[int](op)(chanz.Generate(1, 2), chanz.Generate(3, 4)))
+	for _, out := range chanz.Broadcast(chanz.Generate(1, 2, 3), []chanz.Backpressure{chanz.BackpressureDropNewest, chanz.BackpressureDropOldest}, op) {
+		chanz.DropAll(out, true)
+	}
+
+	chanz.SetTracker(tracker)
+	defer chanz.SetTracker(nil)
+	chanz.Collect(chanz.ParallelMap(chanz.Generate(1, 2, 3, 4, 5, 6, 7, 8), id, 4))
+	chanz.Collect(chanz.FanIn(chanz.Generate(1, 2), chanz.Generate(3, 4)))
+	for _, out := range chanz.Broadcast(chanz.Generate(1, 2, 3), []chanz.Backpressure{chanz.BackpressureDropNewest, chanz.BackpressureDropOldest}) {
+		chanz.DropAll(out, true)
+	}
+	waitStages(t, tracker, 0)
+}
@@ -0,0 +1,99 @@
+package mon
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how Retry retries a failing func.
+// The zero value makes a single attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Less than 1 is treated as 1.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. The delay is doubled for every following retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter is the fraction, between 0 and 1, of each delay that is randomized.
+	// With a Jitter of 0.2 a delay of 1s becomes a random delay between 0.8s and 1s.
+	Jitter float64
+	// Retryable decides if an error is worth retrying. Nil means that every error is.
+	Retryable func(err error) bool
+	// Context stops the retries once it is done. Nil means context.Background().
+	Context context.Context
+	// Sleep waits for d, or returns the error of ctx if it is done first. Nil means a timer based sleep.
+	// Replace it to make retries deterministic in tests.
+	Sleep func(ctx context.Context, d time.Duration) error
+	// Rand returns a random number in [0, 1) used for jitter. Nil means math/rand.Float64.
+	Rand func() float64
+}
+
+// Delay returns the delay before retry number retry, where 1 is the first retry, including jitter.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && d > 0 && d < math.MaxInt64/2 && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		random := rand.Float64
+		if p.Rand != nil {
+			random = p.Rand
+		}
+		d -= time.Duration(float64(d) * jitter * random())
+	}
+	return d
+}
+
+// Retry calls f until it succeeds, returns an error that is not Retryable, MaxAttempts is reached or the Context
+// is done, waiting the Delay of the policy between attempts. The Result holds the value of the successful attempt
+// or the error of the last attempt, or the error of the Context if it was done before the first attempt.
+func Retry[T any](policy RetryPolicy, f func() (T, error)) Result[T] {
+	ctx := policy.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sleep := policy.Sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Err[T](err)
+	}
+	var r Result[T]
+	for attempt := 1; ; attempt++ {
+		r = TupleToResult(f())
+		if r.Ok() || attempt >= policy.MaxAttempts {
+			return r
+		}
+		if policy.Retryable != nil && !policy.Retryable(r.err) {
+			return r
+		}
+		if sleep(ctx, policy.Delay(attempt)) != nil {
+			return r
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package mon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// sleeper records the delays Retry sleeps for, without sleeping
+type sleeper struct {
+	delays []time.Duration
+}
+
+func (s *sleeper) Sleep(ctx context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return ctx.Err()
+}
+
+func failing(failures int, err error) (func() (int, error), *int) {
+	calls := 0
+	return func() (int, error) {
+		calls++
+		if calls <= failures {
+			return 0, err
+		}
+		return calls, nil
+	}, &calls
+}
+
+func equalDelays(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRetry(t *testing.T) {
+	s := &sleeper{}
+	f, calls := failing(2, errors.New("flaky"))
+	r := Retry(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, Sleep: s.Sleep}, f)
+
+	if v, err := r.Get(); err != nil || v != 3 {
+		t.Errorf("Expected Ok(3), got %v, %v", v, err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+	exp := []time.Duration{time.Second, 2 * time.Second}
+	if !equalDelays(s.delays, exp) {
+		t.Errorf("Expected delays %v, got %v", exp, s.delays)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	s := &sleeper{}
+	errFlaky := errors.New("flaky")
+	f, calls := failing(10, errFlaky)
+	r := Retry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, Sleep: s.Sleep}, f)
+
+	if r.Error() != errFlaky {
+		t.Errorf("Expected last error, got %v", r.Error())
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+	if len(s.delays) != 2 {
+		t.Errorf("Expected 2 sleeps, got %v", s.delays)
+	}
+}
+
+func TestRetryZeroPolicy(t *testing.T) {
+	f, calls := failing(1, errors.New("flaky"))
+	r := Retry(RetryPolicy{}, f)
+	if r.Ok() || *calls != 1 {
+		t.Errorf("Expected a single failed attempt, got %v after %d calls", r, *calls)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	s := &sleeper{}
+	errFatal := errors.New("fatal")
+	f, calls := failing(10, errFatal)
+	r := Retry(RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return err != errFatal },
+		Sleep:       s.Sleep,
+	}, f)
+
+	if r.Error() != errFatal || *calls != 1 || len(s.delays) != 0 {
+		t.Errorf("Expected to give up at once, got %v after %d calls", r.Error(), *calls)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errFlaky := errors.New("flaky")
+	f, calls := failing(10, errFlaky)
+	r := Retry(RetryPolicy{
+		MaxAttempts: 10,
+		Context:     ctx,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			cancel()
+			return ctx.Err()
+		},
+	}, f)
+	if r.Error() != errFlaky || *calls != 1 {
+		t.Errorf("Expected to stop after the first attempt, got %v after %d calls", r.Error(), *calls)
+	}
+
+	r = Retry(RetryPolicy{Context: ctx}, f)
+	if r.Error() != context.Canceled || *calls != 1 {
+		t.Errorf("Expected context error without any attempt, got %v after %d calls", r.Error(), *calls)
+	}
+}
+
+func TestRetryDefaultSleep(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	f, calls := failing(10, errors.New("flaky"))
+	start := time.Now()
+	Retry(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, Context: ctx}, f)
+	if *calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("Expected the sleep to be cut short by the context, got %d calls after %v", *calls, time.Since(start))
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	var res []time.Duration
+	for retry := 1; retry <= 6; retry++ {
+		res = append(res, p.Delay(retry))
+	}
+	exp := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	if !equalDelays(res, exp) {
+		t.Errorf("Expected delays %v, got %v", exp, res)
+	}
+
+	p.Jitter = 0.5
+	p.Rand = func() float64 { return 0.5 }
+	if d := p.Delay(1); d != 75*time.Millisecond {
+		t.Errorf("Expected 75ms, got %v", d)
+	}
+
+	p = RetryPolicy{BaseDelay: time.Second}
+	if d := p.Delay(1000); d <= 0 {
+		t.Errorf("Expected a positive delay without overflow, got %v", d)
+	}
+}